@@ -0,0 +1,87 @@
+// Package ase implements encoding and decoding of Adobe Swatch Exchange
+// (.ase) files
+package ase
+
+import "fmt"
+
+const (
+	signature    = "ASEF"
+	versionMajor = 1
+	versionMinor = 0
+)
+
+// block types
+const (
+	blockColor      uint16 = 0x0001
+	blockGroupStart uint16 = 0xC001
+	blockGroupEnd   uint16 = 0xC002
+)
+
+// color models
+const (
+	modelRGB  = "RGB "
+	modelCMYK = "CMYK"
+	modelLAB  = "LAB "
+	modelGray = "Gray"
+)
+
+// ColorType is the swatch type of a Color
+type ColorType uint16
+
+const (
+	Global ColorType = iota
+	Spot
+	Normal
+)
+
+// Color is a single named RGB swatch, with components in the range 0-1
+type Color struct {
+	Name    string
+	R, G, B float32
+	Type    ColorType
+}
+
+// RGB returns the color components scaled to 0-255
+func (c Color) RGB() (r, g, b int) {
+	return scale(c.R), scale(c.G), scale(c.B)
+}
+
+// NewColor returns a Normal Color from the given 0-255 components
+func NewColor(name string, r, g, b int) Color {
+	return Color{
+		Name: name,
+		R:    float32(r) / 255,
+		G:    float32(g) / 255,
+		B:    float32(b) / 255,
+		Type: Normal,
+	}
+}
+
+func (c Color) String() string {
+	r, g, b := c.RGB()
+	return fmt.Sprintf("%s [%03d %03d %03d]", c.Name, r, g, b)
+}
+
+// Group is a named collection of colors
+type Group struct {
+	Name   string
+	Colors []Color
+}
+
+// Document is the contents of an ASE file; colors outside of any group
+// followed by zero or more groups
+type Document struct {
+	Colors []Color
+	Groups []Group
+}
+
+func scale(n float32) int {
+	switch {
+	case n <= 0:
+		return 0
+	case n >= 1:
+		return 255
+	default:
+		return int(n*255 + 0.5)
+	}
+}
@@ -0,0 +1,179 @@
+package ase
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	doc := Document{
+		Colors: []Color{
+			NewColor("loose", 12, 34, 56),
+			{Name: "spot", R: 1, G: 0.5, B: 0, Type: Spot},
+		},
+		Groups: []Group{
+			{
+				Name: "first",
+				Colors: []Color{
+					NewColor("Background", 0, 0, 0),
+					NewColor("red", 255, 0, 0),
+					{Name: "global", R: 0.25, G: 0.5, B: 0.75, Type: Global},
+				},
+			},
+			{Name: "empty"},
+			{
+				Name:   "ünïcödé ✓",
+				Colors: []Color{NewColor("", 1, 2, 3), NewColor("😀 emoji", 4, 5, 6)},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, doc); err != nil {
+		t.Fatalf("Encode: %s", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %s", err)
+	}
+
+	// groups without colors decode with a nil color slice
+	if !reflect.DeepEqual(*got, doc) {
+		t.Errorf("round trip mismatch\n got: %+v\nwant: %+v", *got, doc)
+	}
+}
+
+func TestRoundTripRGB(t *testing.T) {
+	for v := 0; v < 256; v++ {
+		var buf bytes.Buffer
+		doc := Document{Colors: []Color{NewColor("c", v, 255-v, v/2)}}
+		if err := Encode(&buf, doc); err != nil {
+			t.Fatalf("Encode: %s", err)
+		}
+		got, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("Decode: %s", err)
+		}
+		r, g, b := got.Colors[0].RGB()
+		if r != v || g != 255-v || b != v/2 {
+			t.Errorf("got %d %d %d, want %d %d %d", r, g, b, v, 255-v, v/2)
+		}
+	}
+}
+
+// colorBlock returns an encoded color block with the given model and
+// component values
+func colorBlock(name, model string, values ...float32) []byte {
+	var data bytes.Buffer
+	data.Write(encodeName(name))
+	data.WriteString(model)
+	for _, v := range values {
+		write(&data, v)
+	}
+	write(&data, uint16(Normal))
+
+	var buf bytes.Buffer
+	writeBlock(&buf, blockColor, data.Bytes())
+	return buf.Bytes()
+}
+
+// document returns an encoded ASE file of the given blocks
+func document(blocks ...[]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(signature)
+	write(&buf, uint16(versionMajor), uint16(versionMinor), uint32(len(blocks)))
+	for _, b := range blocks {
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeColorModels(t *testing.T) {
+	tests := []struct {
+		model   string
+		values  []float32
+		want    [3]int
+		wantErr string
+	}{
+		{model: modelRGB, values: []float32{1, 0.5, 0}, want: [3]int{255, 128, 0}},
+		{model: modelGray, values: []float32{0.5}, want: [3]int{128, 128, 128}},
+		{model: modelCMYK, values: []float32{0, 0, 0, 0}, want: [3]int{255, 255, 255}},
+		{model: modelCMYK, values: []float32{1, 0, 1, 0}, want: [3]int{0, 255, 0}},
+		{model: modelCMYK, values: []float32{0, 0, 0, 1}, want: [3]int{0, 0, 0}},
+		{model: modelLAB, values: []float32{50, 0, 0}, wantErr: "unsupported color model"},
+		{model: modelRGB, values: []float32{1, 0.5}, wantErr: "malformed RGB"},
+		{model: modelCMYK, values: []float32{1}, wantErr: "malformed CMYK"},
+	}
+
+	for _, tt := range tests {
+		doc, err := Decode(bytes.NewReader(document(colorBlock("c", tt.model, tt.values...))))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q %v: got error %v, want %q", tt.model, tt.values, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q %v: Decode: %s", tt.model, tt.values, err)
+			continue
+		}
+		if len(doc.Colors) != 1 {
+			t.Errorf("%q %v: got %d colors, want 1", tt.model, tt.values, len(doc.Colors))
+			continue
+		}
+		c := doc.Colors[0]
+		if r, g, b := c.RGB(); [3]int{r, g, b} != tt.want {
+			t.Errorf("%q %v: got %d %d %d, want %v", tt.model, tt.values, r, g, b, tt.want)
+		}
+		if c.Name != "c" || c.Type != Normal {
+			t.Errorf("%q %v: got name %q type %d", tt.model, tt.values, c.Name, c.Type)
+		}
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	doc := Document{Groups: []Group{{Name: "group", Colors: []Color{NewColor("red", 255, 0, 0)}}}}
+	if err := Encode(&buf, doc); err != nil {
+		t.Fatalf("Encode: %s", err)
+	}
+	b := buf.Bytes()
+
+	// every proper prefix of a valid file must fail to decode
+	for n := 0; n < len(b); n++ {
+		if _, err := Decode(bytes.NewReader(b[:n])); err == nil {
+			t.Errorf("decoding %d of %d bytes succeeded, want error", n, len(b))
+		}
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	var groupStart, groupEnd, unknown bytes.Buffer
+	writeBlock(&groupStart, blockGroupStart, encodeName("g"))
+	writeBlock(&groupEnd, blockGroupEnd, nil)
+	writeBlock(&unknown, 0x0042, nil)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"empty", nil, "missing ASE signature"},
+		{"bad signature", []byte("ASEX\x00\x01\x00\x00\x00\x00\x00\x00"), "missing ASE signature"},
+		{"bad version", []byte("ASEF\x00\x02\x00\x00\x00\x00\x00\x00"), "unsupported version"},
+		{"nested group", document(groupStart.Bytes(), groupStart.Bytes()), "nested groups"},
+		{"unexpected group end", document(groupEnd.Bytes()), "unexpected group end"},
+		{"unterminated group", document(groupStart.Bytes()), "unterminated group"},
+		{"unknown block", document(unknown.Bytes()), "unknown block type"},
+		{"missing block", document(groupStart.Bytes(), groupEnd.Bytes())[:12+groupStart.Len()], "block 1: malformed header"},
+	}
+
+	for _, tt := range tests {
+		_, err := Decode(bytes.NewReader(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
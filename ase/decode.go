@@ -0,0 +1,152 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"unicode/utf16"
+)
+
+// Decode reads an ASE document from r
+func Decode(r io.Reader) (*Document, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewReader(b)
+
+	sig := make([]byte, len(signature))
+	if _, err := io.ReadFull(buf, sig); err != nil || string(sig) != signature {
+		return nil, fmt.Errorf("missing ASE signature")
+	}
+
+	var header struct {
+		Major, Minor uint16
+		BlockCount   uint32
+	}
+	if err := binary.Read(buf, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("malformed header: %s", err)
+	}
+	if header.Major != versionMajor {
+		return nil, fmt.Errorf("unsupported version %d.%d", header.Major, header.Minor)
+	}
+
+	doc := &Document{}
+	var group *Group
+
+	for n := uint32(0); n < header.BlockCount; n++ {
+		var bh struct {
+			Type   uint16
+			Length uint32
+		}
+		if err := binary.Read(buf, binary.BigEndian, &bh); err != nil {
+			return nil, fmt.Errorf("block %d: malformed header: %s", n, err)
+		}
+		if int64(bh.Length) > int64(buf.Len()) {
+			return nil, fmt.Errorf("block %d: length exceeds file size", n)
+		}
+		data := make([]byte, bh.Length)
+		if _, err := io.ReadFull(buf, data); err != nil {
+			return nil, fmt.Errorf("block %d: malformed data: %s", n, err)
+		}
+
+		switch bh.Type {
+		case blockGroupStart:
+			if group != nil {
+				return nil, fmt.Errorf("block %d: nested groups are not supported", n)
+			}
+			name, err := decodeName(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %s", n, err)
+			}
+			group = &Group{Name: name}
+		case blockGroupEnd:
+			if group == nil {
+				return nil, fmt.Errorf("block %d: unexpected group end", n)
+			}
+			doc.Groups = append(doc.Groups, *group)
+			group = nil
+		case blockColor:
+			c, err := decodeColor(data)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %s", n, err)
+			}
+			if group != nil {
+				group.Colors = append(group.Colors, c)
+			} else {
+				doc.Colors = append(doc.Colors, c)
+			}
+		default:
+			return nil, fmt.Errorf("block %d: unknown block type 0x%04X", n, bh.Type)
+		}
+	}
+
+	if group != nil {
+		return nil, fmt.Errorf("unterminated group \"%s\"", group.Name)
+	}
+
+	return doc, nil
+}
+
+func decodeColor(data []byte) (c Color, err error) {
+	r := bytes.NewReader(data)
+
+	if c.Name, err = decodeName(r); err != nil {
+		return c, err
+	}
+
+	model := make([]byte, 4)
+	if _, err := io.ReadFull(r, model); err != nil {
+		return c, fmt.Errorf("malformed color entry")
+	}
+
+	switch string(model) {
+	case modelRGB:
+		var v [3]float32
+		if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+			return c, fmt.Errorf("malformed RGB color entry")
+		}
+		c.R, c.G, c.B = v[0], v[1], v[2]
+	case modelGray:
+		var v float32
+		if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+			return c, fmt.Errorf("malformed Gray color entry")
+		}
+		c.R, c.G, c.B = v, v, v
+	case modelCMYK:
+		var v [4]float32
+		if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+			return c, fmt.Errorf("malformed CMYK color entry")
+		}
+		k := 1 - v[3]
+		c.R, c.G, c.B = (1-v[0])*k, (1-v[1])*k, (1-v[2])*k
+	default:
+		return c, fmt.Errorf("unsupported color model \"%s\"", string(model))
+	}
+
+	var ct uint16
+	if err := binary.Read(r, binary.BigEndian, &ct); err != nil {
+		return c, fmt.Errorf("malformed color entry (missing color type)")
+	}
+	c.Type = ColorType(ct)
+
+	return c, nil
+}
+
+// decodeName reads a length-prefixed, null-terminated UTF-16 string
+func decodeName(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", fmt.Errorf("malformed name")
+	}
+	units := make([]uint16, n)
+	if err := binary.Read(r, binary.BigEndian, units); err != nil {
+		return "", fmt.Errorf("malformed name")
+	}
+	for len(units) > 0 && units[len(units)-1] == 0 {
+		units = units[:len(units)-1]
+	}
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,64 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+)
+
+// Encode writes doc to w in ASE format
+func Encode(w io.Writer, doc Document) error {
+	var buf bytes.Buffer
+
+	blockCount := len(doc.Colors)
+	for _, g := range doc.Groups {
+		blockCount += len(g.Colors) + 2
+	}
+
+	buf.WriteString(signature)
+	write(&buf, uint16(versionMajor), uint16(versionMinor), uint32(blockCount))
+
+	for _, c := range doc.Colors {
+		writeColor(&buf, c)
+	}
+	for _, g := range doc.Groups {
+		writeBlock(&buf, blockGroupStart, encodeName(g.Name))
+		for _, c := range g.Colors {
+			writeColor(&buf, c)
+		}
+		writeBlock(&buf, blockGroupEnd, nil)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeColor(buf *bytes.Buffer, c Color) {
+	var data bytes.Buffer
+	data.Write(encodeName(c.Name))
+	data.WriteString(modelRGB)
+	write(&data, c.R, c.G, c.B, uint16(c.Type))
+	writeBlock(buf, blockColor, data.Bytes())
+}
+
+func writeBlock(buf *bytes.Buffer, blockType uint16, data []byte) {
+	write(buf, blockType, uint32(len(data)))
+	buf.Write(data)
+}
+
+// encodeName returns s as a length-prefixed, null-terminated UTF-16 string
+func encodeName(s string) []byte {
+	var buf bytes.Buffer
+	units := append(utf16.Encode([]rune(s)), 0)
+	write(&buf, uint16(len(units)), units)
+	return buf.Bytes()
+}
+
+// write encodes each of the given values in big-endian order
+func write(buf *bytes.Buffer, values ...interface{}) {
+	for _, v := range values {
+		// writes to a bytes.Buffer of fixed-size values cannot fail
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
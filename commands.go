@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bcicen/tcolors/state"
+)
+
+// command is a command line command, run with any arguments following the
+// command name
+type command struct {
+	name  string
+	usage string
+	args  []string // completions for the first argument
+	files bool     // complete the first argument as a file path
+	run   func(d *Display, args []string) (resize bool, err error)
+}
+
+var errUnsaved = fmt.Errorf("unsaved changes (save with :w or discard with :q!)")
+
+var commands = []command{
+	{name: "set", usage: "set hex|rgb|hsv|alpha <value>", args: []string{"hex", "rgb", "hsv", "alpha"}, run: cmdSet},
+	{name: "add", usage: "add [count]", run: cmdAdd},
+	{name: "rm", usage: "rm [count]", run: cmdRemove},
+	{name: "sort", usage: "sort " + strings.Join(state.SortKeys, "|"), args: state.SortKeys, run: cmdSort},
+	{name: "w", usage: "w [path]", files: true, run: cmdWrite},
+	{name: "e", usage: "e <path>", files: true, run: cmdEdit},
+	{name: "tabe", usage: "tabe <path>", files: true, run: cmdTabEdit},
+	{name: "tabc", usage: "tabc", run: cmdTabClose},
+	{name: "tabc!", usage: "tabc!", run: cmdTabForceClose},
+	{name: "tabn", usage: "tabn", run: cmdTabNext},
+	{name: "tabp", usage: "tabp", run: cmdTabPrev},
+	{name: "wq", usage: "wq", run: cmdWriteQuit},
+	{name: "q", usage: "q", run: cmdQuit},
+	{name: "q!", usage: "q!", run: cmdForceQuit},
+}
+
+// argError is an error in the arguments given to a command
+type argError struct{ msg string }
+
+func (e argError) Error() string { return e.msg }
+
+func argErr(format string, a ...interface{}) error {
+	return argError{fmt.Sprintf(format, a...)}
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// RunCommand parses and runs a command line, returning whether the display
+// should be resized
+func (d *Display) RunCommand(line string) (resize bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+
+	c, ok := lookupCommand(fields[0])
+	if !ok {
+		return false, fmt.Errorf("unknown command: %s", fields[0])
+	}
+	resize, err = c.run(d, fields[1:])
+	if err != nil {
+		if _, ok := err.(argError); ok {
+			return resize, fmt.Errorf("%s (usage: %s)", err, c.usage)
+		}
+		return resize, fmt.Errorf("%s: %s", c.name, err)
+	}
+	return resize, nil
+}
+
+// completeCommand returns completions for a partial command line
+func completeCommand(line string) (cands []string) {
+	fields := strings.Fields(line)
+	trailing := strings.HasSuffix(line, " ")
+
+	// complete command name
+	if len(fields) == 0 || (len(fields) == 1 && !trailing) {
+		prefix := ""
+		if len(fields) == 1 {
+			prefix = fields[0]
+		}
+		for _, c := range commands {
+			if strings.HasPrefix(c.name, prefix) {
+				cands = append(cands, c.name)
+			}
+		}
+		return cands
+	}
+
+	// complete first argument
+	if len(fields) > 2 || (len(fields) == 2 && trailing) {
+		return nil
+	}
+	c, ok := lookupCommand(fields[0])
+	if !ok {
+		return nil
+	}
+	prefix := ""
+	if len(fields) == 2 {
+		prefix = fields[1]
+	}
+
+	var args []string
+	if c.files {
+		args = completePath(prefix)
+	}
+	for _, arg := range c.args {
+		if strings.HasPrefix(arg, prefix) {
+			args = append(args, arg)
+		}
+	}
+	for _, arg := range args {
+		cands = append(cands, c.name+" "+arg)
+	}
+	return cands
+}
+
+// completePath returns file paths beginning with prefix, with a trailing
+// separator for directories
+func completePath(prefix string) []string {
+	matches, _ := filepath.Glob(prefix + "*")
+	sort.Strings(matches)
+	for n, m := range matches {
+		if fi, err := os.Stat(m); err == nil && fi.IsDir() {
+			matches[n] = m + string(filepath.Separator)
+		}
+	}
+	return matches
+}
+
+func cmdSet(d *Display, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, argErr("missing color format")
+	}
+	format, vals := args[0], args[1:]
+
+	var err error
+	switch format {
+	case "hex":
+		if len(vals) != 1 {
+			return false, argErr("expected 1 value")
+		}
+		err = d.state.SetHex(vals[0])
+	case "rgb":
+		var n []int
+		if n, err = parseInts(vals, 3); err == nil {
+			err = d.state.SetRGB(n[0], n[1], n[2])
+		}
+	case "hsv":
+		var n []float64
+		if n, err = parseFloats(vals, 3); err == nil {
+			err = d.state.SetHSV(n[0], n[1], n[2])
+		}
+	case "alpha":
+		var n []float64
+		if n, err = parseFloats(vals, 1); err == nil {
+			d.state.SetAlpha(n[0])
+			d.EnableAlpha()
+		}
+	default:
+		return false, argErr("unknown color format: %s", format)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	d.build()
+	return false, nil
+}
+
+func cmdAdd(d *Display, args []string) (bool, error) {
+	count, err := parseCount(args)
+	if err != nil {
+		return false, err
+	}
+	if err := d.state.FixedSize(); err != nil {
+		return false, err
+	}
+	for n := 0; n < count; n++ {
+		if !d.state.Add() {
+			return n > 0, fmt.Errorf("maximum palette size reached")
+		}
+	}
+	return true, nil
+}
+
+func cmdRemove(d *Display, args []string) (bool, error) {
+	count, err := parseCount(args)
+	if err != nil {
+		return false, err
+	}
+	if err := d.state.FixedSize(); err != nil {
+		return false, err
+	}
+	for n := 0; n < count; n++ {
+		if !d.state.Remove() {
+			return n > 0, fmt.Errorf("palette must have at least one color")
+		}
+	}
+	return true, nil
+}
+
+func cmdSort(d *Display, args []string) (bool, error) {
+	if len(args) != 1 {
+		return false, argErr("expected 1 sort key")
+	}
+	if err := d.state.Sort(args[0]); err != nil {
+		return false, err
+	}
+	d.build()
+	return false, nil
+}
+
+func cmdWrite(d *Display, args []string) (bool, error) {
+	switch len(args) {
+	case 0:
+		return false, d.state.Save()
+	case 1:
+		return false, d.state.SaveTo(args[0])
+	}
+	return false, argErr("too many arguments")
+}
+
+func cmdEdit(d *Display, args []string) (bool, error) {
+	if len(args) != 1 {
+		return false, argErr("expected 1 path")
+	}
+	if err := d.OpenPalette(args[0]); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func cmdTabEdit(d *Display, args []string) (bool, error) {
+	if len(args) != 1 {
+		return false, argErr("expected 1 path")
+	}
+	return false, d.OpenTab(args[0])
+}
+
+func cmdTabClose(d *Display, args []string) (bool, error) {
+	return false, d.CloseTab(false)
+}
+
+func cmdTabForceClose(d *Display, args []string) (bool, error) {
+	return false, d.CloseTab(true)
+}
+
+func cmdTabNext(d *Display, args []string) (bool, error) {
+	d.SwitchTab(d.tab + 1)
+	return false, nil
+}
+
+func cmdTabPrev(d *Display, args []string) (bool, error) {
+	d.SwitchTab(d.tab - 1)
+	return false, nil
+}
+
+func cmdQuit(d *Display, args []string) (bool, error) {
+	if len(d.dirtyTabs()) > 0 {
+		return false, errUnsaved
+	}
+	close(d.quit)
+	return false, nil
+}
+
+func cmdWriteQuit(d *Display, args []string) (bool, error) {
+	if err := d.state.Save(); err != nil {
+		return false, err
+	}
+	close(d.quit)
+	return false, nil
+}
+
+// cmdForceQuit exits without saving
+func cmdForceQuit(d *Display, args []string) (bool, error) {
+	d.discard = true
+	close(d.quit)
+	return false, nil
+}
+
+// parseCount returns the optional count argument, defaulting to 1
+func parseCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := parseInts(args, 1)
+	if err != nil {
+		return 0, err
+	}
+	if n[0] < 1 {
+		return 0, argErr("invalid count: %d", n[0])
+	}
+	return n[0], nil
+}
+
+func parseInts(args []string, count int) ([]int, error) {
+	if len(args) != count {
+		return nil, argErr("expected %d values", count)
+	}
+	a := make([]int, count)
+	for n, arg := range args {
+		i, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, argErr("invalid number: %s", arg)
+		}
+		a[n] = i
+	}
+	return a, nil
+}
+
+func parseFloats(args []string, count int) ([]float64, error) {
+	if len(args) != count {
+		return nil, argErr("expected %d values", count)
+	}
+	a := make([]float64, count)
+	for n, arg := range args {
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, argErr("invalid number: %s", arg)
+		}
+		a[n] = f
+	}
+	return a, nil
+}
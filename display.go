@@ -2,11 +2,15 @@ package main
 
 import (
 	"fmt"
+	"image"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/bcicen/tcolors/keys"
 	"github.com/bcicen/tcolors/state"
 	"github.com/bcicen/tcolors/styles"
+	"github.com/bcicen/tcolors/watch"
 	"github.com/bcicen/tcolors/widgets"
 	"github.com/gdamore/tcell"
 	"github.com/teacat/noire"
@@ -15,12 +19,21 @@ import (
 const (
 	paddingX   = 2
 	minWidth   = 26
+	minBoxW    = 3 // minimum width of each palette color box
 	minHeight  = 22
+	alphaRows  = 3 // rows occupied by the alpha bar, when enabled
 	maxWidth   = 105
 	littleStep = 1
 	bigStep    = 10
+
+	autosaveDelay = 2 * time.Second
+	watchSettle   = 100 * time.Millisecond // delay before reloading a changed palette
 )
 
+// paletteChanged is posted to the event loop when the palette file is
+// modified
+type paletteChanged struct{}
+
 type ChangeHandler interface {
 	Handle(state.Change)
 }
@@ -32,38 +45,59 @@ type Section interface {
 	Draw(int, int, tcell.Screen) int
 	Resize(int, int) // resize section to given width and height
 	SetPointerStyle(tcell.Style)
+	HitTest(int, int) bool // report whether screen coordinates fall within section
+	Click(int, int)        // set section value from screen coordinates
 }
 
 type Display struct {
 	rgb       []int32
 	sections  []Section
 	sectionN  int
+	dragN     int // section receiving mouse drag events, or -1
 	xPos      int
 	width     int
 	stepBasis int
+	keymap    *keys.Keymap
 	menu      widgets.MenuFn
 	errMsg    *widgets.ErrorMsg
-	state     *state.State
+	cmdline   *widgets.CommandLine
+	prompt    *widgets.Prompt
+	dropper   *widgets.Eyedropper
+	alpha     bool           // alpha bar enabled
+	state     *state.State   // palette of the active tab
+	tabs      []*state.State // open palettes, one per tab
+	tab       int            // index of the active tab
+	tabX      [][2]int       // screen columns of each tab label, as of last draw
+	clip      *state.Clip    // yanked color, if any
+	screen    tcell.Screen
+	watcher   *watch.Watcher
 	quit      chan struct{}
+	discard   bool // exit without saving
 	lock      sync.RWMutex
 }
 
-func NewDisplay(s tcell.Screen, tstate *state.State) *Display {
+// NewDisplay returns a Display for the given palettes, each opened in a
+// tab with the first active. If alpha is set, the alpha bar is shown from
+// the start.
+func NewDisplay(s tcell.Screen, tstates []*state.State, km *keys.Keymap, alpha bool) *Display {
 	d := &Display{
-		state:  tstate,
-		errMsg: widgets.NewErrorMsg(),
-		quit:   make(chan struct{}),
-		sections: []Section{
-			widgets.NewPaletteBox(tstate),
-			widgets.NewHueBar(tstate),
-			widgets.NewSaturationBar(tstate),
-			widgets.NewValueBar(tstate),
-		},
+		screen:  s,
+		state:   tstates[0],
+		tabs:    tstates,
+		keymap:  km,
+		errMsg:  widgets.NewErrorMsg(),
+		cmdline: widgets.NewCommandLine(completeCommand),
+		prompt:  widgets.NewPrompt(),
+		quit:    make(chan struct{}),
+		dragN:   -1,
+		alpha:   alpha,
 	}
+	d.sections = d.newSections()
 
 	w, h := s.Size()
 	d.Resize(w, h)
 	d.build()
+	d.checkTab()
 
 	//if d.state.IsNew() {
 	//msg := fmt.Sprintf("creating new palette file: %s", d.state.Path())
@@ -71,25 +105,183 @@ func NewDisplay(s tcell.Screen, tstate *state.State) *Display {
 	//}
 
 	go d.eventHandler(s)
+	go d.autosave()
+	d.watchPalette()
 	return d
 }
 
-func (d *Display) Done() error {
+// watchPalette watches the palette file for changes made by other programs,
+// replacing any existing watch
+func (d *Display) watchPalette() {
+	if d.watcher != nil {
+		d.watcher.Close()
+	}
+	w, err := watch.New(d.state.Path())
+	if err != nil {
+		log.Warningf("failed to watch palette: %s", err)
+		return
+	}
+	d.watcher = w
+
+	go func() {
+		for {
+			select {
+			case <-d.quit:
+				w.Close()
+				return
+			case <-w.Closed():
+				return
+			case <-w.C:
+			}
+			// allow the writing program to finish
+			time.Sleep(watchSettle)
+			d.screen.PostEvent(tcell.NewEventInterrupt(paletteChanged{}))
+		}
+	}()
+}
+
+// handlePaletteChange reloads the palette when modified by another program,
+// first prompting if there are unsaved changes that would be lost
+func (d *Display) handlePaletteChange() (resize bool) {
+	if !d.state.DiskChanged() {
+		// written by this session
+		return false
+	}
+
+	if !d.state.Dirty() {
+		if err := d.state.Reload(); err != nil {
+			d.errMsg.Set(err.Error())
+			return true
+		}
+		d.build()
+		d.errMsg.Set("palette changed on disk, reloaded")
+		return true
+	}
+
+	d.errMsg.Set("palette changed on disk with unsaved changes")
+	choices := []widgets.PromptChoice{
+		{Key: 'r', Label: "reload"},
+		{Key: 'k', Label: "keep"},
+	}
+	d.prompt.Ask("palette changed on disk:", choices, func(r rune) {
+		switch r {
+		case 'r':
+			if err := d.state.Reload(); err != nil {
+				d.errMsg.Set(err.Error())
+				return
+			}
+			d.state.RemoveRecovery()
+			d.build()
+		case 'k':
+			d.state.IgnoreDiskChange()
+		}
+	})
+	return true
+}
+
+// newSections returns the sections for the palette of the active tab
+func (d *Display) newSections() []Section {
+	sections := []Section{
+		widgets.NewPaletteBox(d.state),
+		widgets.NewHueBar(d.state),
+		widgets.NewSaturationBar(d.state),
+		widgets.NewValueBar(d.state),
+	}
+	if d.alpha {
+		sections = append(sections, widgets.NewAlphaBar(d.state))
+	}
+	return sections
+}
+
+// offerRecovery prompts to restore unsaved changes from a previous session,
+// if any were recovered
+func (d *Display) offerRecovery() {
+	modTime, ok := d.state.Recovery()
+	if !ok {
+		return
+	}
+
+	question := fmt.Sprintf("restore unsaved changes from %s?", modTime.Format("Jan 2 15:04"))
+	choices := []widgets.PromptChoice{
+		{Key: 'r', Label: "restore"},
+		{Key: 'd', Label: "discard"},
+	}
+	d.prompt.Ask(question, choices, func(r rune) {
+		switch r {
+		case 'r':
+			if err := d.state.Recover(); err != nil {
+				d.errMsg.Set(fmt.Sprintf("failed to restore changes: %s", err))
+				return
+			}
+			d.build()
+		case 'd':
+			d.state.RemoveRecovery()
+		}
+	})
+}
+
+// autosave periodically writes unsaved changes to the recovery file of
+// each open palette, once no further changes have been made for
+// autosaveDelay
+func (d *Display) autosave() {
+	lastRev := make(map[*state.State]uint64)
+	savedRev := make(map[*state.State]uint64)
 	for {
 		select {
 		case <-d.quit:
-			return d.state.Save()
-		case <-time.After(time.Millisecond * 50):
+			return
+		case <-time.After(autosaveDelay):
+		}
+
+		for _, st := range d.openTabs() {
+			rev := st.Revision()
+			if rev != lastRev[st] {
+				// changes still being made
+				lastRev[st] = rev
+				continue
+			}
+			if rev == savedRev[st] || !st.Dirty() || st.ReadOnly() {
+				continue
+			}
+			if err := st.WriteRecovery(); err != nil {
+				log.Warningf("autosave failed: %s", err)
+				continue
+			}
+			savedRev[st] = rev
 		}
 	}
 }
 
+// Done waits for the Display to exit, then saves or discards changes to
+// each open palette and releases their locks. The first error in saving is
+// returned.
+func (d *Display) Done() error {
+	<-d.quit
+
+	var err error
+	for _, st := range d.openTabs() {
+		switch {
+		case d.discard:
+			// the recovery file of a read-only palette is another session's
+			if !st.ReadOnly() {
+				st.RemoveRecovery()
+			}
+		case (st.Dirty() || st.IsNew()) && !st.ReadOnly():
+			if serr := st.Save(); serr != nil && err == nil {
+				err = serr
+			}
+		}
+		st.Close()
+	}
+	return err
+}
+
 func (d *Display) drawSizeErr(s tcell.Screen) {
 	w, h := s.Size()
 	st := styles.Error
 	s.SetCell(1, 0, st, []rune("screen too small!")...)
 	s.SetCell(1, 1, st, []rune(fmt.Sprintf("[cur] %dx%d", w, h))...)
-	s.SetCell(1, 2, st, []rune(fmt.Sprintf("[min] %dx%d", minWidth, minHeight))...)
+	s.SetCell(1, 2, st, []rune(fmt.Sprintf("[min] %dx%d", d.minWidth(), d.minHeight()))...)
 	s.Show()
 }
 
@@ -114,8 +306,7 @@ func (d *Display) Draw(s tcell.Screen) {
 		s.SetCell(x, y, styles.TextBox, '⏵')
 	}
 
-	sname := d.state.Name()
-	s.SetCell((x+d.width)-len(sname), y, styles.TextBox, []rune(sname)...)
+	d.drawTabs(x, y, s)
 	y += 1
 
 	// draw sections
@@ -128,17 +319,38 @@ func (d *Display) Draw(s tcell.Screen) {
 		y += sec.Draw(x, y, s)
 	}
 	d.errMsg.Draw(x, s)
+	d.cmdline.Draw(x, s)
+	d.prompt.Draw(x, s)
 
 	log.Noticef("lightness = %f", d.state.Selected().Lightness())
 
 	s.Show()
 }
 
+// minWidth returns the minimum screen width required to display all
+// palette colors
+func (d *Display) minWidth() int {
+	w := d.state.Len()*minBoxW + (paddingX * 2) + 1
+	if w < minWidth {
+		return minWidth
+	}
+	return w
+}
+
+// minHeight returns the minimum screen height required to display all
+// sections
+func (d *Display) minHeight() int {
+	if d.alpha {
+		return minHeight + alphaRows
+	}
+	return minHeight
+}
+
 func (d *Display) Resize(w, h int) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	if w < minWidth || h < minHeight {
+	if w < d.minWidth() || h < d.minHeight() {
 		d.width = -1
 		return
 	}
@@ -150,15 +362,26 @@ func (d *Display) Resize(w, h int) {
 
 	// ensure total width aligns well with palette count
 	d.width = (d.width / d.state.Len()) * d.state.Len()
+	// size sections as without the alpha bar, leaving room for it
+	sh := h
+	if d.alpha {
+		sh -= alphaRows
+	}
 	for _, sec := range d.sections {
-		sec.Resize(d.width, h)
+		sec.Resize(d.width, sh)
 	}
 	d.errMsg.Resize(d.width)
+	d.cmdline.Resize(d.width)
+	d.prompt.Resize(d.width)
 
 	d.xPos = (w - d.width) / 2 // center display
 }
 
 func (d *Display) build() {
+	if !d.alpha && d.state.HasAlpha() {
+		d.EnableAlpha()
+	}
+
 	change := d.state.Flush()
 	log.Debugf("handling change: %08b", change)
 	log.Debugf("state: [h=%0.3f s=%0.3f v=%0.3f]", d.state.Hue(), d.state.Saturation(), d.state.Value())
@@ -181,6 +404,129 @@ func (d *Display) SetColor(c tcell.Color) {
 	d.build()
 }
 
+// EnableAlpha adds the alpha bar, for editing color opacity
+func (d *Display) EnableAlpha() {
+	if d.alpha {
+		return
+	}
+	bar := widgets.NewAlphaBar(d.state)
+	bar.Handle(state.AllChanged)
+
+	d.lock.Lock()
+	d.alpha = true
+	d.sections = append(d.sections, bar)
+	d.lock.Unlock()
+
+	// resize from the event loop to make room for the new section
+	w, h := d.screen.Size()
+	d.screen.PostEvent(tcell.NewEventResize(w, h))
+}
+
+// SetImage sets the image used by the eyedropper
+func (d *Display) SetImage(img image.Image) {
+	d.dropper = widgets.NewEyedropper(img, d.SetColor)
+}
+
+// resizePalette adds or removes a palette color with fn, reporting an error
+// instead if the palette file format requires a fixed number of colors
+func (d *Display) resizePalette(fn func() bool) (redraw, resize bool) {
+	if err := d.state.FixedSize(); err != nil {
+		d.errMsg.Set(err.Error())
+		return true, false
+	}
+	return false, fn()
+}
+
+// Eyedropper opens the eyedropper for the current image, if any
+func (d *Display) Eyedropper() (ok bool) {
+	if d.dropper == nil {
+		d.errMsg.Set("no image loaded (see -image)")
+		return true
+	}
+	d.menu = d.dropper.Menu
+	return true
+}
+
+// OpenPalette replaces the displayed palette with the palette stored at
+// path, refusing if there are unsaved changes. A palette already open in
+// another tab is switched to instead.
+func (d *Display) OpenPalette(path string) error {
+	if n, ok := d.findTab(path); ok {
+		d.SwitchTab(n)
+		return nil
+	}
+	if d.state.Dirty() {
+		return errUnsaved
+	}
+	if err := d.state.Open(path); err != nil {
+		return err
+	}
+	d.watchPalette()
+	d.build()
+	d.checkTab()
+	return nil
+}
+
+// Save saves the palette, reporting the result in the message line
+func (d *Display) Save() (ok bool) {
+	if err := d.state.Save(); err != nil {
+		d.errMsg.Set(err.Error())
+		return true
+	}
+	d.errMsg.Set(fmt.Sprintf("saved %s", d.state.Path()))
+	return true
+}
+
+// Quit exits tcolors, first prompting to save or discard any unsaved changes
+// in open palettes
+func (d *Display) Quit() (ok bool) {
+	dirty := d.dirtyTabs()
+	if len(dirty) == 0 {
+		close(d.quit)
+		return false
+	}
+
+	var writable int
+	var readOnly []string
+	for _, st := range dirty {
+		if st.ReadOnly() {
+			readOnly = append(readOnly, st.Name())
+		} else {
+			writable++
+		}
+	}
+
+	question := "save changes?"
+	if writable > 1 {
+		question = fmt.Sprintf("save changes to %d palettes?", writable)
+	}
+	if len(readOnly) > 0 {
+		question = fmt.Sprintf("%s (read-only, not saved: %s)", question, strings.Join(readOnly, ", "))
+	}
+	choices := []widgets.PromptChoice{
+		{Key: 's', Label: "save"},
+		{Key: 'd', Label: "discard"},
+		{Key: 'c', Label: "cancel"},
+	}
+	if writable == 0 {
+		question = "palette is read-only, discard changes?"
+		if len(readOnly) > 1 {
+			question = "palettes are read-only, discard changes?"
+		}
+		choices = choices[1:]
+	}
+	d.prompt.Ask(question, choices, func(r rune) {
+		switch r {
+		case 's':
+			close(d.quit)
+		case 'd':
+			d.discard = true
+			close(d.quit)
+		}
+	})
+	return true
+}
+
 func (d *Display) SectionUp() (ok bool) {
 	if d.sectionN == 0 {
 		return false
@@ -209,7 +555,217 @@ func (d *Display) ValueDown() (ok bool) {
 	return true
 }
 
+// HandleMouse routes mouse events to the Section under the cursor. Clicking
+// focuses a Section and sets its value, with dragging continuing to update
+// the same Section until release. The scroll wheel steps the focused Section.
+func (d *Display) HandleMouse(ev *tcell.EventMouse) (ok bool) {
+	x, y := ev.Position()
+	btn := ev.Buttons()
+
+	switch {
+	case btn&tcell.WheelUp != 0:
+		return d.ValueUp()
+	case btn&tcell.WheelDown != 0:
+		return d.ValueDown()
+	case btn&tcell.Button1 != 0 && y == 0 && d.dragN < 0:
+		// header tab bar
+		for n, tx := range d.tabX {
+			if x >= tx[0] && x < tx[1] {
+				d.SwitchTab(n)
+				return true
+			}
+		}
+		return false
+	case btn&tcell.Button1 != 0:
+		if d.dragN < 0 {
+			for n, sec := range d.sections {
+				if sec.HitTest(x, y) {
+					d.dragN = n
+					break
+				}
+			}
+			if d.dragN < 0 {
+				return false
+			}
+			d.sectionN = d.dragN
+		}
+		d.sections[d.dragN].Click(x, y)
+		d.build()
+		return true
+	default:
+		// button released
+		d.dragN = -1
+	}
+	return false
+}
+
+// mouse bindings, listed in the help menu after key bindings
+var mouseHelpItems = []widgets.HelpMenuItem{
+	{Key: "<click>, <drag>", Desc: "select a color or set the value under the cursor"},
+	{Key: "<click> on a tab", Desc: "switch to the palette in that tab"},
+	{Key: "<scroll>", Desc: "increase/decrease selected value"},
+}
+
+// actionFn performs a named action, returning whether the display should
+// be redrawn or resized
+type actionFn func(s tcell.Screen) (redraw, resize bool)
+
+// actions returns the handler for each action in keys.Actions
+func (d *Display) actions() map[string]actionFn {
+	return map[string]actionFn{
+		keys.SectionUp:   func(tcell.Screen) (bool, bool) { return d.SectionUp(), false },
+		keys.SectionDown: func(tcell.Screen) (bool, bool) { return d.SectionDown(), false },
+		keys.ValueDecrease: func(tcell.Screen) (bool, bool) {
+			return d.ValueDown(), false
+		},
+		keys.ValueIncrease: func(tcell.Screen) (bool, bool) {
+			return d.ValueUp(), false
+		},
+		keys.ValueDecreaseBig: func(tcell.Screen) (bool, bool) {
+			d.stepBasis = bigStep
+			return d.ValueDown(), false
+		},
+		keys.ValueIncreaseBig: func(tcell.Screen) (bool, bool) {
+			d.stepBasis = bigStep
+			return d.ValueUp(), false
+		},
+		keys.PaletteAdd:        func(tcell.Screen) (bool, bool) { return d.resizePalette(d.state.Add) },
+		keys.PaletteRemove:     func(tcell.Screen) (bool, bool) { return d.resizePalette(d.state.Remove) },
+		keys.PaletteEyedropper: func(tcell.Screen) (bool, bool) { return d.Eyedropper(), false },
+		keys.ScreenRedraw: func(s tcell.Screen) (bool, bool) {
+			s.Sync()
+			return false, false
+		},
+		keys.Command: func(tcell.Screen) (bool, bool) {
+			d.cmdline.Open()
+			return true, false
+		},
+		keys.PaletteLibrary: func(tcell.Screen) (bool, bool) {
+			lb := widgets.NewLibraryBrowser(state.LibraryDir(), d.isOpen, d.OpenPalette)
+			d.menu = lb.Menu
+			return false, false
+		},
+		keys.PaletteYank:  func(tcell.Screen) (bool, bool) { return d.Yank(), false },
+		keys.PalettePaste: func(tcell.Screen) (bool, bool) { return d.Paste(), false },
+		keys.TabNext: func(tcell.Screen) (bool, bool) {
+			d.SwitchTab(d.tab + 1)
+			return false, false
+		},
+		keys.TabPrev: func(tcell.Screen) (bool, bool) {
+			d.SwitchTab(d.tab - 1)
+			return false, false
+		},
+		keys.PaletteInfo: func(s tcell.Screen) (bool, bool) {
+			d.menu = d.infoMenu(s)
+			return false, false
+		},
+		keys.Help: func(tcell.Screen) (bool, bool) {
+			d.menu = d.helpMenu()
+			return false, false
+		},
+		keys.Save: func(tcell.Screen) (bool, bool) { return d.Save(), false },
+		keys.Quit: func(tcell.Screen) (bool, bool) { return d.Quit(), false },
+		keys.QuitDiscard: func(tcell.Screen) (bool, bool) {
+			d.discard = true
+			close(d.quit)
+			return false, false
+		},
+	}
+}
+
+// helpMenu returns a help menu listing the active key bindings
+func (d *Display) helpMenu() widgets.MenuFn {
+	var items []widgets.HelpMenuItem
+	for _, a := range keys.Actions {
+		bound := d.keymap.Keys(a.Name)
+		if len(bound) == 0 {
+			continue
+		}
+		names := make([]string, len(bound))
+		for n, k := range bound {
+			names[n] = k.String()
+		}
+		items = append(items, widgets.HelpMenuItem{Key: strings.Join(names, ", "), Desc: a.Desc})
+	}
+	return widgets.NewHelpMenu(append(items, mouseHelpItems...))
+}
+
+// infoMenu returns a menu listing the palette name, file and metadata, with
+// long values wrapped to the screen width
+func (d *Display) infoMenu(s tcell.Screen) widgets.MenuFn {
+	fields := append([]state.MetaField{
+		{Label: "Name", Value: d.state.Name()},
+		{Label: "File", Value: d.state.Path()},
+		{Label: "Colors", Value: fmt.Sprintf("%d", d.state.Len())},
+	}, d.state.Metadata().Fields()...)
+
+	var labelW int
+	for _, f := range fields {
+		if len(f.Label) > labelW {
+			labelW = len(f.Label)
+		}
+	}
+	w, _ := s.Size()
+	valueW := w - labelW - 6
+	if valueW < minWidth {
+		valueW = minWidth
+	}
+
+	var items []widgets.HelpMenuItem
+	for _, f := range fields {
+		for n, line := range wrapWords(f.Value, valueW) {
+			item := widgets.HelpMenuItem{Desc: line}
+			if n == 0 {
+				item.Key = f.Label
+			}
+			items = append(items, item)
+		}
+	}
+	return widgets.NewHelpMenu(items)
+}
+
+// wrapWords splits s into lines of at most width characters, breaking
+// between words where possible
+func wrapWords(s string, width int) []string {
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		if len(line) > 0 && len(line)+1+len(w) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, w...)
+		for len(line) > width {
+			lines = append(lines, string(line[:width]))
+			line = line[width:]
+		}
+	}
+	return append(lines, string(line))
+}
+
+// handleCommandKey passes a key event to the command line, running the
+// entered command on submission
+func (d *Display) handleCommandKey(s tcell.Screen, ev *tcell.EventKey) (redraw, resize bool) {
+	line, done := d.cmdline.HandleKey(ev)
+	if !done {
+		return true, false
+	}
+	s.HideCursor()
+
+	resize, err := d.RunCommand(line)
+	if err != nil {
+		d.errMsg.Set(err.Error())
+	}
+	return true, resize
+}
+
 func (d *Display) eventHandler(s tcell.Screen) {
+	actions := d.actions()
+
 	for {
 		redraw := false
 		resize := false
@@ -218,61 +774,42 @@ func (d *Display) eventHandler(s tcell.Screen) {
 		ev := s.PollEvent()
 		switch ev := ev.(type) {
 		case *tcell.EventKey:
-			if ev.Modifiers()&tcell.ModShift == tcell.ModShift && d.sectionN != 0 {
-				d.stepBasis = bigStep
+			if d.prompt.Active() {
+				// answers may replace the palette, requiring a resize
+				resize = d.prompt.HandleKey(ev)
+				break
 			}
-			switch ev.Key() {
-			case tcell.KeyRune:
-				switch ev.Rune() {
-				case 'H':
-					d.stepBasis = bigStep
-					redraw = d.ValueDown()
-				case 'L':
-					d.stepBasis = bigStep
-					redraw = d.ValueUp()
-				case 'h':
-					redraw = d.ValueDown()
-				case 'k':
-					redraw = d.SectionUp()
-				case 'j':
-					redraw = d.SectionDown()
-				case 'l':
-					redraw = d.ValueUp()
-				case 'a':
-					resize = d.state.Add()
-				case 'x':
-					resize = d.state.Remove()
-				case '?':
-					d.menu = widgets.HelpMenu
-				case 'q':
-					close(d.quit)
-					return
-				default:
-					log.Debugf("ignoring rune key [%s]", string(ev.Rune()))
-				}
-			case tcell.KeyRight:
-				redraw = d.ValueUp()
-			case tcell.KeyLeft:
-				redraw = d.ValueDown()
-			case tcell.KeyUp:
-				redraw = d.SectionUp()
-			case tcell.KeyDown:
-				redraw = d.SectionDown()
-			case tcell.KeyEscape, tcell.KeyCtrlC:
-				close(d.quit)
-				return
-			case tcell.KeyCtrlL:
-				s.Sync()
-			case tcell.KeyInsert:
-				resize = d.state.Add()
-			case tcell.KeyDelete:
-				resize = d.state.Remove()
-			default:
+			if d.cmdline.Active() {
+				redraw, resize = d.handleCommandKey(s, ev)
+				break
+			}
+			name, ok := d.keymap.Action(ev)
+			if !ok {
 				log.Debugf("ignoring event key [%s]", ev.Name())
+				break
 			}
+			redraw, resize = actions[name](s)
+
+		case *tcell.EventMouse:
+			if d.prompt.Active() || d.cmdline.Active() {
+				// input is modal until answered or dismissed
+				break
+			}
+			redraw = d.HandleMouse(ev)
 
 		case *tcell.EventResize:
 			resize = true
+
+		case *tcell.EventInterrupt:
+			if _, ok := ev.Data().(paletteChanged); ok {
+				resize = d.handlePaletteChange()
+			}
+		}
+
+		select {
+		case <-d.quit:
+			return
+		default:
 		}
 
 		for d.menu != nil {
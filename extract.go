@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bcicen/tcolors/extract"
+	"github.com/bcicen/tcolors/state"
+)
+
+// runExtract implements the extract subcommand, writing a new palette file
+// from the colors of an image
+func runExtract(args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	var (
+		countFlag = fs.Int("n", 8, "number of palette colors to extract")
+		outFlag   = fs.String("o", "", "palette file to create (default <image name>.toml)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: tcolors extract [-n COUNT] [-o PALETTE_FILE] IMAGE_FILE\n")
+		fs.PrintDefaults()
+	}
+
+	// allow flags both before and after the image path
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *countFlag < 1 || *countFlag > state.MaxColors {
+		fmt.Fprintf(os.Stderr, "color count must be between 1 and %d\n", state.MaxColors)
+		fs.Usage()
+		os.Exit(2)
+	}
+	imgPath := positional[0]
+
+	base := strings.TrimSuffix(filepath.Base(imgPath), filepath.Ext(imgPath))
+	outPath := *outFlag
+	if outPath == "" {
+		outPath = base + ".toml"
+	}
+	if _, err := os.Stat(outPath); err == nil {
+		errExit(fmt.Errorf("palette file %s already exists", outPath))
+	}
+
+	f, err := os.Open(imgPath)
+	errExit(err)
+	defer f.Close()
+
+	p, err := extract.Extract(f, *countFlag)
+	errExit(err)
+
+	colors := make([]color.Color, len(p.Colors))
+	for n := range p.Colors {
+		colors[n] = p.Colors[n]
+	}
+
+	tstate, err := state.FromColors(outPath, base, p.Background, colors)
+	errExit(err)
+	errExit(tstate.Save())
+
+	fmt.Printf("extracted %d colors from %s to %s\n", tstate.Len(), imgPath, outPath)
+}
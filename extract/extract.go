@@ -0,0 +1,110 @@
+// Package extract derives color palettes from images
+package extract
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	maxSamples = 1 << 16 // maximum pixels sampled from an image
+	minAlpha   = 0x8000  // ignore mostly transparent pixels
+)
+
+// Palette is a set of colors extracted from an image
+type Palette struct {
+	Background color.RGBA
+	Colors     []color.RGBA
+}
+
+// Extract decodes a PNG, JPEG or GIF image from r and returns a Palette of n
+// colors, plus a background. Colors are clustered in OKLab space; the
+// background is the darkest of the dominant clusters, and the remaining
+// colors are ordered by hue.
+func Extract(r io.Reader, n int) (*Palette, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("color count must be at least 1")
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %s", err)
+	}
+
+	samples := sample(img)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("image has no opaque pixels")
+	}
+
+	clusters := kmeans(samples, n+1)
+	if len(clusters) < 2 {
+		return nil, fmt.Errorf("image has too few distinct colors")
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].size > clusters[j].size })
+
+	// background is the darkest of the most populous half of clusters
+	bgIdx := 0
+	for i := 1; i < (len(clusters)+1)/2; i++ {
+		if clusters[i].center.L < clusters[bgIdx].center.L {
+			bgIdx = i
+		}
+	}
+
+	p := &Palette{Background: clusters[bgIdx].center.RGBA()}
+	var rest []lab
+	for i, c := range clusters {
+		if i != bgIdx {
+			rest = append(rest, c.center)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return hue(rest[i]) < hue(rest[j]) })
+	for _, c := range rest {
+		p.Colors = append(p.Colors, c.RGBA())
+	}
+
+	return p, nil
+}
+
+// sample returns up to maxSamples pixels of img in OKLab, evenly spaced
+func sample(img image.Image) []lab {
+	bounds := img.Bounds()
+	step := int(math.Ceil(math.Sqrt(float64(bounds.Dx()*bounds.Dy()) / maxSamples)))
+	if step < 1 {
+		step = 1
+	}
+
+	var samples []lab
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < minAlpha {
+				continue
+			}
+			// un-premultiply alpha
+			c := color.RGBA{
+				R: uint8(r * 0xffff / a >> 8),
+				G: uint8(g * 0xffff / a >> 8),
+				B: uint8(b * 0xffff / a >> 8),
+				A: 255,
+			}
+			samples = append(samples, toLab(c))
+		}
+	}
+	return samples
+}
+
+// hue returns the OKLab hue angle of c in degrees, 0-360
+func hue(c lab) float64 {
+	h := math.Atan2(c.B, c.A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
@@ -0,0 +1,101 @@
+package extract
+
+import (
+	"math/rand"
+)
+
+const (
+	maxIterations = 24
+	seed          = 1 // fixed seed for reproducible palettes
+)
+
+// cluster is a group of samples around a centroid
+type cluster struct {
+	center lab
+	size   int
+}
+
+// kmeans partitions samples into k clusters, seeded with k-means++
+func kmeans(samples []lab, k int) []cluster {
+	if k > len(samples) {
+		k = len(samples)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	clusters := seedClusters(samples, k, rng)
+	assign := make([]int, len(samples))
+
+	for iter := 0; iter < maxIterations; iter++ {
+		changed := false
+		for i, s := range samples {
+			best := nearest(clusters, s)
+			if best != assign[i] || iter == 0 {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([]lab, len(clusters))
+		for n := range clusters {
+			clusters[n].size = 0
+		}
+		for i, s := range samples {
+			c := assign[i]
+			sums[c].L += s.L
+			sums[c].A += s.A
+			sums[c].B += s.B
+			clusters[c].size++
+		}
+		for n := range clusters {
+			if size := float64(clusters[n].size); size > 0 {
+				clusters[n].center = lab{sums[n].L / size, sums[n].A / size, sums[n].B / size}
+			}
+		}
+	}
+
+	return clusters
+}
+
+// seedClusters picks k initial centers, each chosen with probability
+// proportional to its squared distance from the nearest existing center
+func seedClusters(samples []lab, k int, rng *rand.Rand) []cluster {
+	clusters := []cluster{{center: samples[rng.Intn(len(samples))]}}
+	dists := make([]float64, len(samples))
+
+	for len(clusters) < k {
+		var total float64
+		for i, s := range samples {
+			dists[i] = s.dist(clusters[nearest(clusters, s)].center)
+			total += dists[i]
+		}
+		if total == 0 {
+			// fewer distinct colors than requested clusters
+			break
+		}
+
+		target := rng.Float64() * total
+		i := 0
+		for ; i < len(samples)-1; i++ {
+			target -= dists[i]
+			if target <= 0 {
+				break
+			}
+		}
+		clusters = append(clusters, cluster{center: samples[i]})
+	}
+
+	return clusters
+}
+
+// nearest returns the index of the cluster nearest to s
+func nearest(clusters []cluster, s lab) int {
+	best, bestDist := 0, -1.0
+	for n, c := range clusters {
+		if d := s.dist(c.center); bestDist < 0 || d < bestDist {
+			best, bestDist = n, d
+		}
+	}
+	return best
+}
@@ -0,0 +1,68 @@
+// Package keys provides named actions and configurable key bindings
+package keys
+
+// action names
+const (
+	SectionUp         = "section.up"
+	SectionDown       = "section.down"
+	ValueDecrease     = "value.decrease"
+	ValueIncrease     = "value.increase"
+	ValueDecreaseBig  = "value.decrease.big"
+	ValueIncreaseBig  = "value.increase.big"
+	PaletteAdd        = "palette.add"
+	PaletteRemove     = "palette.remove"
+	PaletteEyedropper = "palette.eyedropper"
+	PaletteInfo       = "palette.info"
+	PaletteLibrary    = "palette.library"
+	PaletteYank       = "palette.yank"
+	PalettePaste      = "palette.paste"
+	TabNext           = "tab.next"
+	TabPrev           = "tab.prev"
+	ScreenRedraw      = "screen.redraw"
+	Command           = "command"
+	Save              = "save"
+	Help              = "help"
+	Quit              = "quit"
+	QuitDiscard       = "quit.discard"
+)
+
+// Action is a named operation that may be bound to one or more keys
+type Action struct {
+	Name     string
+	Desc     string
+	Defaults []string // default key bindings
+}
+
+// Actions is the registry of all bindable actions, in help menu order
+var Actions = []Action{
+	{SectionUp, "navigate up", []string{"up", "k"}},
+	{SectionDown, "navigate down", []string{"down", "j"}},
+	{ValueDecrease, "decrease selected value", []string{"left", "h"}},
+	{ValueIncrease, "increase selected value", []string{"right", "l"}},
+	{ValueDecreaseBig, "more quickly decrease selected value", []string{"shift+left", "H"}},
+	{ValueIncreaseBig, "more quickly increase selected value", []string{"shift+right", "L"}},
+	{PaletteAdd, "add a new palette color", []string{"a", "ins"}},
+	{PaletteRemove, "remove the selected palette color", []string{"x", "del"}},
+	{PaletteEyedropper, "pick the selected color from an image (see -image)", []string{"e"}},
+	{PaletteInfo, "show palette name, file and metadata", []string{"i"}},
+	{PaletteLibrary, "browse, open and manage saved palettes", []string{"o"}},
+	{PaletteYank, "yank (copy) the selected color", []string{"y"}},
+	{PalettePaste, "paste the yanked color over the selected color", []string{"p"}},
+	{TabNext, "switch to the next palette tab", []string{"tab"}},
+	{TabPrev, "switch to the previous palette tab", []string{"backtab"}},
+	{ScreenRedraw, "redraw the screen", []string{"ctrl+l"}},
+	{Command, "open the command line", []string{":"}},
+	{Save, "save the palette", []string{"w"}},
+	{Quit, "exit tcolors, prompting to save any changes", []string{"q", "esc"}},
+	{QuitDiscard, "exit tcolors without saving", []string{"ctrl+c"}},
+	{Help, "show this help menu", []string{"?"}},
+}
+
+func lookupAction(name string) (Action, bool) {
+	for _, a := range Actions {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
@@ -0,0 +1,135 @@
+package keys
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// Key is a single key press, optionally with modifiers
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+	Mod  tcell.ModMask
+}
+
+// symbols used in place of key names when displaying keys
+var keySymbols = map[tcell.Key]string{
+	tcell.KeyUp:     "↑",
+	tcell.KeyDown:   "↓",
+	tcell.KeyLeft:   "←",
+	tcell.KeyRight:  "→",
+	tcell.KeyInsert: "<ins>",
+	tcell.KeyDelete: "<del>",
+}
+
+// additional key names accepted when parsing, beyond those in tcell.KeyNames
+var keyAliases = map[string]tcell.Key{
+	"escape": tcell.KeyEsc,
+	"del":    tcell.KeyDelete,
+	"ins":    tcell.KeyInsert,
+	"return": tcell.KeyEnter,
+}
+
+// FromEvent returns the Key for the given key event. Shift is ignored for
+// runes, being reflected in the rune itself, as is ctrl for control keys.
+func FromEvent(ev *tcell.EventKey) Key {
+	k := Key{Key: ev.Key(), Mod: ev.Modifiers()}
+	switch {
+	case k.Key == tcell.KeyRune:
+		k.Rune = ev.Rune()
+		k.Mod &^= tcell.ModShift
+	case k.Key <= tcell.KeyCtrlUnderscore:
+		k.Mod &^= tcell.ModCtrl
+	}
+	return k
+}
+
+// Parse returns the Key for a key description, such as "k", "H", "up",
+// "shift+left", "ctrl+l" or "del". Key names are case-insensitive.
+func Parse(desc string) (Key, error) {
+	var k Key
+	parts := strings.Split(desc, "+")
+
+	// a trailing empty part means the key itself is "+"
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(mod) {
+		case "shift":
+			k.Mod |= tcell.ModShift
+		case "alt":
+			k.Mod |= tcell.ModAlt
+		case "meta":
+			k.Mod |= tcell.ModMeta
+		case "ctrl":
+			k.Mod |= tcell.ModCtrl
+		default:
+			return k, fmt.Errorf("unknown modifier \"%s\" in \"%s\"", mod, desc)
+		}
+	}
+
+	name := parts[len(parts)-1]
+	if r := []rune(name); len(r) == 1 {
+		if k.Mod&tcell.ModCtrl != 0 {
+			return parseNamed(k, "ctrl-"+name, desc)
+		}
+		k.Key, k.Rune = tcell.KeyRune, r[0]
+		return k, nil
+	}
+	return parseNamed(k, name, desc)
+}
+
+func parseNamed(k Key, name, desc string) (Key, error) {
+	name = strings.ToLower(name)
+	if name == "space" {
+		k.Key, k.Rune = tcell.KeyRune, ' '
+		return k, nil
+	}
+	if key, ok := keyAliases[name]; ok {
+		k.Key = key
+		return k, nil
+	}
+	for key, keyName := range tcell.KeyNames {
+		if strings.ToLower(keyName) == name {
+			k.Key = key
+			if key <= tcell.KeyCtrlUnderscore {
+				k.Mod &^= tcell.ModCtrl
+			}
+			return k, nil
+		}
+	}
+	return k, fmt.Errorf("unknown key \"%s\"", desc)
+}
+
+// String returns a display name for k
+func (k Key) String() string {
+	var s string
+	switch {
+	case k.Key == tcell.KeyRune && k.Rune == ' ':
+		s = "<space>"
+	case k.Key == tcell.KeyRune:
+		s = string(k.Rune)
+	case keySymbols[k.Key] != "":
+		s = keySymbols[k.Key]
+	default:
+		s = "<" + strings.ToLower(tcell.KeyNames[k.Key]) + ">"
+	}
+
+	if k.Mod&tcell.ModCtrl != 0 {
+		s = "<ctrl> + " + s
+	}
+	if k.Mod&tcell.ModAlt != 0 {
+		s = "<alt> + " + s
+	}
+	if k.Mod&tcell.ModMeta != 0 {
+		s = "<meta> + " + s
+	}
+	if k.Mod&tcell.ModShift != 0 {
+		s = "<shift> + " + s
+	}
+	return s
+}
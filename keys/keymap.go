@@ -0,0 +1,101 @@
+package keys
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell"
+)
+
+// Keymap maps keys to action names
+type Keymap struct {
+	actions  map[Key]string
+	bindings map[string][]Key // action name to bound keys, in order
+}
+
+// Default returns a Keymap with the default binding for every action
+func Default() *Keymap {
+	km := &Keymap{
+		actions:  make(map[Key]string),
+		bindings: make(map[string][]Key),
+	}
+	for _, a := range Actions {
+		if err := km.Bind(a.Name, a.Defaults...); err != nil {
+			panic(err)
+		}
+	}
+	return km
+}
+
+// Load returns the default Keymap with any bindings from the keymap file at
+// path applied. Each action listed in the file replaces all of its default
+// bindings, e.g.:
+//
+//	"value.increase" = ["right", "l", "+"]
+//
+// A missing file is not an error.
+func Load(path string) (*Keymap, error) {
+	km := Default()
+
+	var config map[string][]string
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		if os.IsNotExist(err) {
+			return km, nil
+		}
+		return nil, fmt.Errorf("failed to load keymap: %s", err)
+	}
+
+	for name, descs := range config {
+		if err := km.Bind(name, descs...); err != nil {
+			return nil, fmt.Errorf("failed to load keymap: [%s] %s", name, err)
+		}
+	}
+
+	return km, nil
+}
+
+// Bind replaces the keys bound to the named action. Keys bound to another
+// action are rebound to this one.
+func (km *Keymap) Bind(name string, descs ...string) error {
+	if _, ok := lookupAction(name); !ok {
+		return fmt.Errorf("unknown action")
+	}
+
+	for _, k := range km.bindings[name] {
+		delete(km.actions, k)
+	}
+	km.bindings[name] = nil
+
+	for _, desc := range descs {
+		k, err := Parse(desc)
+		if err != nil {
+			return err
+		}
+		if prev, ok := km.actions[k]; ok {
+			km.unbind(prev, k)
+		}
+		km.actions[k] = name
+		km.bindings[name] = append(km.bindings[name], k)
+	}
+	return nil
+}
+
+func (km *Keymap) unbind(name string, k Key) {
+	keys := km.bindings[name][:0]
+	for _, bound := range km.bindings[name] {
+		if bound != k {
+			keys = append(keys, bound)
+		}
+	}
+	km.bindings[name] = keys
+}
+
+// Action returns the name of the action bound to the given key event
+func (km *Keymap) Action(ev *tcell.EventKey) (string, bool) {
+	name, ok := km.actions[FromEvent(ev)]
+	return name, ok
+}
+
+// Keys returns the keys bound to the named action
+func (km *Keymap) Keys(name string) []Key { return km.bindings[name] }
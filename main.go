@@ -3,9 +3,15 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io/ioutil"
 	"os"
 	"strings"
 
+	"github.com/bcicen/tcolors/keys"
 	"github.com/bcicen/tcolors/logging"
 	"github.com/bcicen/tcolors/state"
 	"github.com/bcicen/tcolors/styles"
@@ -23,11 +29,32 @@ var (
 func main() {
 	defer log.Exit()
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "extract":
+			runExtract(os.Args[2:])
+			return
+		case "restore":
+			runRestore(os.Args[2:])
+			return
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		}
+	}
+
+	var files paletteFiles
+	flag.Var(&files, "f", "specify palette file, repeated to open palettes in tabs")
+
 	var (
 		printFlag        = flag.Bool("p", false, "output palette contents")
-		outputFlag       = flag.String("o", "all", "color format to output (hex, rgb, hsv, term, all)")
+		outputFlag       = flag.String("o", "all", "color format to output (hex, rgb, hsv, term, itermcolors, gpl, ase, css, scss, less, tokens, tailwind, vim, nvim, base16, pywal, png, svg, all)")
 		outputOnExitFlag = flag.Bool("output-on-exit", false, "output palette file contents on exit")
-		fileFlag         = flag.String("f", state.DefaultPalettePath, "specify palette file")
+		outFileFlag      = flag.String("out", "", "write output to file instead of stdout")
+		cssStyleFlag     = flag.String("css-style", "hex", "color value style for css, scss and less output (hex, rgb, hsl)")
+		imageFlag        = flag.String("image", "", "image file to sample colors from with the eyedropper")
+		forceFlag        = flag.Bool("force", false, "take over the palette lock from another tcolors process")
+		alphaFlag        = flag.Bool("alpha", false, "show the alpha bar for editing color opacity")
 		versionFlag      = flag.Bool("v", false, "print version info")
 	)
 
@@ -38,16 +65,26 @@ func main() {
 		os.Exit(0)
 	}
 
-	tstate, err := state.Load(*fileFlag)
+	if len(files) == 0 {
+		files = paletteFiles{state.DefaultPalettePath}
+	}
+	tstates, err := loadPalettes(files, *forceFlag, !*printFlag)
 	errExit(err)
+	// the first palette is used for -p output and the initial screen style
+	tstate := tstates[0]
 
-	if *printFlag {
-		printPalette(tstate, *outputFlag)
-		os.Exit(0)
+	km, err := keys.Load(state.DefaultKeymapPath)
+	errExit(err)
+
+	var img image.Image
+	if *imageFlag != "" {
+		img, err = loadImage(*imageFlag)
+		errExit(err)
 	}
 
-	if *outputOnExitFlag {
-		defer printPalette(tstate, *outputFlag)
+	if *printFlag {
+		printPalette(tstate, *outputFlag, *outFileFlag, *cssStyleFlag)
+		os.Exit(0)
 	}
 
 	// initialize screen
@@ -65,10 +102,14 @@ func main() {
 		Foreground(tcell.ColorWhite).
 		Background(tstate.Background()))
 	styles.Load(tstate.Background())
+	s.EnableMouse()
 	s.Clear()
 
 	// initialize Display
-	disp := NewDisplay(s, tstate)
+	disp := NewDisplay(s, tstates, km, *alphaFlag)
+	if img != nil {
+		disp.SetImage(img)
+	}
 
 	err = disp.Done()
 	s.Clear()
@@ -76,24 +117,146 @@ func main() {
 	if err != nil {
 		fmt.Println(err)
 	}
+	if *outputOnExitFlag {
+		printPalette(disp.activeTab(), *outputFlag, *outFileFlag, *cssStyleFlag)
+	}
 }
 
-func printPalette(tstate *state.State, cfmt string) {
+// printPalette writes the palette in the given format to outPath, or stdout
+// if no path is given. Binary formats are always written to a file, named
+// for the palette if no path is given.
+func printPalette(tstate *state.State, cfmt, outPath, cssStyle string) {
+	var out []byte
+
 	cfmt = strings.ToLower(strings.Trim(cfmt, " "))
 	switch cfmt {
 	case "all":
-		fmt.Printf("%s\n", tstate.TableString())
+		out = []byte(tstate.TableString() + "\n")
 	case "hex":
-		fmt.Printf("%s\n", tstate.HexString())
+		out = []byte(tstate.HexString() + "\n")
 	case "hsv":
-		fmt.Printf("%s\n", tstate.HSVString())
+		out = []byte(tstate.HSVString() + "\n")
 	case "rgb":
-		fmt.Printf("%s\n", tstate.RGBString())
+		out = []byte(tstate.RGBString() + "\n")
 	case "term":
-		fmt.Printf("%s\n", tstate.TermString())
+		out = []byte(tstate.TermString() + "\n")
+	case "itermcolors":
+		out = []byte(tstate.ITermString())
+	case "gpl":
+		out = []byte(tstate.GPLString())
+	case "css", "scss", "less":
+		style, err := state.ParseCSSStyle(cssStyle)
+		errExit(err)
+		switch cfmt {
+		case "css":
+			out = []byte(tstate.CSSString(style))
+		case "scss":
+			out = []byte(tstate.SCSSString(style))
+		case "less":
+			out = []byte(tstate.LessString(style))
+		}
+	case "vim":
+		out = []byte(tstate.VimString())
+	case "nvim":
+		out = []byte(tstate.NvimString())
+	case "base16":
+		txt, err := tstate.Base16String()
+		errExit(err)
+		out = []byte(txt)
+	case "pywal":
+		txt, err := tstate.PywalString()
+		errExit(err)
+		out = []byte(txt)
+	case "tokens":
+		out = []byte(tstate.TokensString())
+	case "tailwind":
+		out = []byte(tstate.TailwindString())
+	case "ase":
+		out = tstate.ASEBytes()
+		if outPath == "" {
+			outPath = tstate.Name() + ".ase"
+		}
+	case "png":
+		b, err := tstate.PNGBytes()
+		errExit(err)
+		out = b
+		if outPath == "" {
+			outPath = tstate.Name() + ".png"
+		}
+	case "svg":
+		out = []byte(tstate.SVGString())
 	default:
 		errExit(fmt.Errorf("unknown format \"%s\"", cfmt))
 	}
+
+	if outPath == "" {
+		os.Stdout.Write(out)
+		return
+	}
+	errExit(ioutil.WriteFile(outPath, out, 0644))
+	fmt.Printf("wrote %s output to %s\n", cfmt, outPath)
+}
+
+// paletteFiles is a repeatable flag, collecting a palette file path from
+// each use
+type paletteFiles []string
+
+func (pf *paletteFiles) String() string { return strings.Join(*pf, ", ") }
+
+func (pf *paletteFiles) Set(path string) error {
+	*pf = append(*pf, path)
+	return nil
+}
+
+// loadPalettes loads each of the given palettes once, however the path is
+// given. Palettes loaded for editing are locked, taking over their locks if
+// force is set.
+func loadPalettes(paths []string, force, edit bool) ([]*state.State, error) {
+	var tstates []*state.State
+next:
+	for _, path := range paths {
+		for _, tstate := range tstates {
+			if state.SamePath(tstate.Path(), path) {
+				continue next
+			}
+		}
+
+		if !edit {
+			tstate, err := state.Read(path)
+			if err != nil {
+				return nil, err
+			}
+			tstates = append(tstates, tstate)
+			continue
+		}
+
+		tstate, err := state.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if force {
+			if err := tstate.TakeLock(); err != nil {
+				return nil, err
+			}
+		}
+		tstates = append(tstates, tstate)
+	}
+	return tstates, nil
+}
+
+// loadImage decodes a PNG, JPEG or GIF image from the given path
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %s", path, err)
+	}
+	return img, nil
 }
 
 func errExit(err error) {
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bcicen/tcolors/state"
+)
+
+// runRestore implements the restore subcommand, listing the backups of a
+// palette file or restoring one of them
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	var (
+		fileFlag = fs.String("f", state.DefaultPalettePath, "palette file to restore")
+		nFlag    = fs.Int("n", 0, "backup number to restore (default list backups)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: tcolors restore [-f PALETTE_FILE] [-n BACKUP]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *nFlag != 0 {
+		errExit(state.Restore(*fileFlag, *nFlag))
+		fmt.Printf("restored backup %d of %s\n", *nFlag, *fileFlag)
+		return
+	}
+
+	backups, err := state.Backups(*fileFlag)
+	errExit(err)
+	if len(backups) == 0 {
+		fmt.Printf("no backups of %s\n", *fileFlag)
+		return
+	}
+
+	fmt.Printf("backups of %s (restore with -n):\n", *fileFlag)
+	for _, b := range backups {
+		summary := "unreadable"
+		if bstate, err := b.Load(); err == nil {
+			summary = fmt.Sprintf("%d colors: %s", bstate.Len(), bstate.HexString())
+		}
+		fmt.Printf("  %d  %s  %s\n", b.N, b.ModTime.Format(time.RFC822), summary)
+	}
+}
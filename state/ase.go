@@ -0,0 +1,67 @@
+package state
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/bcicen/tcolors/ase"
+)
+
+const aseBackgroundName = "Background"
+
+// ASEBytes returns the current State encoded as an Adobe Swatch Exchange file
+func (s *State) ASEBytes() []byte {
+	var buf bytes.Buffer
+	encodeASE(&buf, s.config())
+	return buf.Bytes()
+}
+
+// encodeASE writes config as a single ASE group named for the palette, with
+// the background as its first color
+func encodeASE(w io.Writer, config PaletteConfig) error {
+	group := ase.Group{Name: config.Name}
+	group.Colors = append(group.Colors, aseColor(aseBackgroundName, config.Background))
+	for _, pc := range config.Colors {
+		group.Colors = append(group.Colors, aseColor(pc.Name, pc))
+	}
+	return ase.Encode(w, ase.Document{Groups: []ase.Group{group}})
+}
+
+func aseColor(name string, pc paletteColor) ase.Color {
+	return ase.NewColor(name, pc.RGB[0], pc.RGB[1], pc.RGB[2])
+}
+
+// decodeASE reads an ASE file into a PaletteConfig. Ungrouped colors are
+// read first, followed by the colors of each group; the first group name
+// is used as the palette name
+func decodeASE(b []byte) (*PaletteConfig, error) {
+	doc, err := ase.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	config := PaletteConfig{Version: paletteVersion}
+	colors := doc.Colors
+	for _, g := range doc.Groups {
+		if config.Name == "" {
+			config.Name = g.Name
+		}
+		colors = append(colors, g.Colors...)
+	}
+
+	var hasBackground bool
+	for _, c := range colors {
+		r, g, b := c.RGB()
+		pc := paletteColor{Name: c.Name, RGB: []int{r, g, b}}
+		if !hasBackground && strings.EqualFold(c.Name, aseBackgroundName) {
+			pc.Name = ""
+			config.Background = pc
+			hasBackground = true
+			continue
+		}
+		config.Colors = append(config.Colors, pc)
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BackupCount is the number of previous saves retained for each palette
+const BackupCount = 5
+
+// Backup is a previously saved version of a palette file
+type Backup struct {
+	N       int // 1 is the most recent
+	Path    string
+	ModTime time.Time
+	palette string // path of the palette the backup was made from
+}
+
+// backupPath returns the path of the nth backup of the palette at path
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", path, n)
+}
+
+// Backups returns the available backups of the palette at path, most
+// recent first
+func Backups(path string) ([]Backup, error) {
+	var backups []Backup
+	for n := 1; n <= BackupCount; n++ {
+		bpath := backupPath(path, n)
+		fi, err := os.Stat(bpath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		backups = append(backups, Backup{N: n, Path: bpath, ModTime: fi.ModTime(), palette: path})
+	}
+	return backups, nil
+}
+
+// Load reads the backup in the format of the palette it was made from
+func (b Backup) Load() (*State, error) {
+	data, err := ioutil.ReadFile(b.Path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := codecFor(b.palette).decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewDefault()
+	s.path = b.palette
+	if err := s.apply(config); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Restore replaces the palette file with the contents of backup n, refusing
+// if the palette is open for editing. The replaced palette is itself kept as
+// the most recent backup.
+func Restore(path string, n int) error {
+	if err := checkUnlocked(path); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(backupPath(path, n))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no backup %d of %s", n, path)
+		}
+		return err
+	}
+	return writeAtomic(path, true, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic writes the output of fn to a temporary file in the same
+// directory as path, syncing and renaming it over path once complete, such
+// that path is never left partially written. A symlinked path has its
+// target replaced, keeping the link. If backup is set, the existing file is
+// first rotated into the backup history.
+func writeAtomic(path string, backup bool, fn func(io.Writer) error) error {
+	target := resolveLink(path)
+	dir, base := filepath.Split(target)
+	if dir == "" {
+		dir = "."
+	}
+
+	mode := os.FileMode(0644)
+	fi, statErr := os.Stat(target)
+	if statErr == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	f, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if backup && statErr == nil {
+		if err := rotateBackups(path); err != nil {
+			log.Warningf("failed to back up %s: %s", path, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// rotateBackups shifts existing backups of path back by one, discarding the
+// oldest, and keeps the current file as the most recent backup
+func rotateBackups(path string) error {
+	os.Remove(backupPath(path, BackupCount))
+	for n := BackupCount - 1; n >= 1; n-- {
+		err := os.Rename(backupPath(path, n), backupPath(path, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	// link where possible, leaving the current file in place until replaced
+	target := resolveLink(path)
+	if err := os.Link(target, backupPath(path, 1)); err == nil {
+		return nil
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(backupPath(path, 1), data, fi.Mode().Perm()); err != nil {
+		return err
+	}
+	// the created mode is subject to umask
+	return os.Chmod(backupPath(path, 1), fi.Mode().Perm())
+}
+
+// resolveLink returns the file a symlinked path points to, or path itself
+// if it is not a symlink or cannot be resolved
+func resolveLink(path string) string {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		return target
+	}
+	return path
+}
+
+// syncDir flushes directory entries to disk, ensuring a completed rename
+// survives a crash. Errors are ignored as not all platforms support it.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const gplNoBackground = `GIMP Palette
+Name: plain
+#
+255   0   0	red
+  0 255   0	green
+`
+
+// writeBackedUp writes each of contents to path in turn, backing up the
+// previous contents on each write
+func writeBackedUp(t *testing.T, path string, contents ...string) {
+	for _, c := range contents {
+		err := writeAtomic(path, true, func(w io.Writer) error {
+			_, err := io.WriteString(w, c)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("writeAtomic: %s", err)
+		}
+	}
+}
+
+func TestBackupLoadNoBackground(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "plain.gpl")
+	writeBackedUp(t, path, gplNoBackground, gplHeader+"\n0 0 255\tblue\n")
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatalf("Backups: %s", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1", len(backups))
+	}
+
+	s, err := backups[0].Load()
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if s.background == nil {
+		t.Fatal("backup loaded without a background")
+	}
+	// as listed by tcolors restore
+	if got := s.HexString(); got != "000000, FF0000, 00FF00" {
+		t.Errorf("HexString() = %q", got)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "plain.gpl")
+	latest := gplHeader + "\n0 0 255\tblue\n"
+	writeBackedUp(t, path, gplNoBackground, latest)
+
+	if err := Restore(path, 1); err != nil {
+		t.Fatalf("Restore: %s", err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != gplNoBackground {
+		t.Errorf("restored palette = %q, want %q", b, gplNoBackground)
+	}
+
+	// the replaced palette is kept as the most recent backup
+	b, err = ioutil.ReadFile(backupPath(path, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != latest {
+		t.Errorf("backup 1 = %q, want %q", b, latest)
+	}
+
+	if err := Restore(path, 3); err == nil || !strings.Contains(err.Error(), "no backup 3") {
+		t.Errorf("Restore of missing backup: got error %v", err)
+	}
+}
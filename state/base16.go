@@ -0,0 +1,127 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	base16Count         = 16
+	base16BackgroundKey = "background" // non-standard, set if not base00
+)
+
+// Base16String returns the current State as a base16 scheme. The palette
+// must have exactly 16 colors, mapped in order to base00-base0F.
+func (s *State) Base16String() (string, error) {
+	var buf bytes.Buffer
+	if err := encodeBase16(&buf, s.config()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func base16Key(n int) string { return fmt.Sprintf("base%02X", n) }
+
+// encodeBase16 writes config as a base16 scheme
+func encodeBase16(w io.Writer, config PaletteConfig) error {
+	if len(config.Colors) != base16Count {
+		return fmt.Errorf("base16 schemes require %d colors (palette has %d)", base16Count, len(config.Colors))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "scheme: %s\n", strconv.Quote(config.Name))
+	fmt.Fprintf(&buf, "author: %s\n", strconv.Quote(config.Author))
+	for n, pc := range config.Colors {
+		fmt.Fprintf(&buf, "%s: \"%s\"\n", base16Key(n), strings.ToLower(pc.HEX))
+	}
+	// base00 is the scheme background; any other is kept in an extra key
+	if bg := strings.ToLower(config.Background.HEX); bg != "" && bg != strings.ToLower(config.Colors[0].HEX) {
+		fmt.Fprintf(&buf, "%s: \"%s\"\n", base16BackgroundKey, bg)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// decodeBase16 reads a base16 scheme into a PaletteConfig, with base00-base0F
+// as palette colors and base00 additionally used as the background, unless
+// another is given by a background key
+func decodeBase16(b []byte) (*PaletteConfig, error) {
+	config := PaletteConfig{Version: paletteVersion}
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	lineN := 0
+	for scanner.Scan() {
+		lineN++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineN)
+		}
+		key := strings.TrimSpace(line[:i])
+		values[key] = yamlScalar(line[i+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	config.Name = values["scheme"]
+	config.Author = values["author"]
+
+	for n := 0; n < base16Count; n++ {
+		key := base16Key(n)
+		hex, ok := values[key]
+		if !ok {
+			// base16 keys are case-insensitive in practice (base0a vs base0A)
+			hex, ok = values[strings.ToLower(key)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing %s", key)
+		}
+		config.Colors = append(config.Colors, paletteColor{HEX: strings.TrimPrefix(hex, "#")})
+	}
+	config.Background = config.Colors[0]
+	if hex, ok := values[base16BackgroundKey]; ok {
+		config.Background = paletteColor{HEX: strings.TrimPrefix(hex, "#")}
+	}
+
+	return &config, nil
+}
+
+// yamlScalar returns the unquoted value of a single-line YAML scalar,
+// stripping any trailing comment
+func yamlScalar(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "\""):
+		for end := 1; end < len(s); end++ {
+			if s[end] == '\\' {
+				end++
+				continue
+			}
+			if s[end] == '"' {
+				if v, err := strconv.Unquote(s[:end+1]); err == nil {
+					return v
+				}
+				return s[1:end]
+			}
+		}
+	case strings.HasPrefix(s, "'"):
+		if end := strings.IndexByte(s[1:], '\''); end >= 0 {
+			return s[1 : end+1]
+		}
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,132 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// base16State returns a State of 16 distinct colors over the given
+// background
+func base16State(t *testing.T, bg []int) *State {
+	var colors [][]int
+	for n := 0; n < base16Count; n++ {
+		colors = append(colors, []int{n * 16, 255 - n*16, n})
+	}
+	return testState(t, bg, colors...)
+}
+
+func TestBase16RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		bg   []int
+	}{
+		{name: "base00 background", bg: []int{0, 255, 0}},
+		{name: "other background", bg: []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		s := base16State(t, tt.bg)
+		s.name = "Round \"Trip\""
+		s.meta.Author = "A. Author"
+		want := s.config()
+
+		got := roundTrip(t, codecs[".yaml"], want)
+		checkRGB(t, *got, want)
+		if got.Name != want.Name || got.Author != want.Author {
+			t.Errorf("%s: scheme %q by %q, want %q by %q", tt.name, got.Name, got.Author, want.Name, want.Author)
+		}
+	}
+}
+
+func TestBase16WrongSize(t *testing.T) {
+	s := testState(t, []int{0, 0, 0}, []int{255, 0, 0})
+	if _, err := s.Base16String(); err == nil {
+		t.Error("Base16String() succeeded for 1 color, want error")
+	}
+}
+
+func TestDecodeBase16(t *testing.T) {
+	var keys []string
+	for n := 0; n < base16Count; n++ {
+		keys = append(keys, fmt.Sprintf("%s: \"%02x%02x%02x\"", base16Key(n), n, n, n))
+	}
+	scheme := strings.Join(keys, "\n")
+
+	tests := []struct {
+		name    string
+		in      string
+		wantBg  string
+		wantErr string
+	}{
+		{name: "plain", in: "scheme: \"x\"\n" + scheme, wantBg: "000000"},
+		{name: "document start", in: "---\n# comment\n" + scheme, wantBg: "000000"},
+		{name: "lowercase keys", in: strings.Replace(scheme, "base0A", "base0a", 1), wantBg: "000000"},
+		{name: "background", in: scheme + "\nbackground: \"#102030\"", wantBg: "102030"},
+		{name: "missing key", in: strings.Replace(scheme, "base0F", "baseXX", 1), wantErr: "missing base0F"},
+		{name: "malformed line", in: scheme + "\nnot yaml", wantErr: "line 17"},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeBase16([]byte(tt.in))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: decodeBase16() returned error: %s", tt.name, err)
+			continue
+		}
+		if len(got.Colors) != base16Count {
+			t.Errorf("%s: got %d colors, want %d", tt.name, len(got.Colors), base16Count)
+		}
+		if got.Background.HEX != tt.wantBg {
+			t.Errorf("%s: background = %q, want %q", tt.name, got.Background.HEX, tt.wantBg)
+		}
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{` plain `, "plain"},
+		{`plain # comment`, "plain"},
+		{`"quoted # not comment"`, "quoted # not comment"},
+		{`"esc\"aped" # comment`, `esc"aped`},
+		{`'single' # comment`, "single"},
+		{`"unterminated`, `"unterminated`},
+	}
+
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBase16SaveReload(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "scheme.yaml")
+
+	s := base16State(t, []int{1, 2, 3})
+	s.path = path
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+
+	got, err := read(path)
+	if err != nil {
+		t.Fatalf("reload: %s", err)
+	}
+	if got.Len() != base16Count {
+		t.Errorf("reloaded %d colors, want %d", got.Len(), base16Count)
+	}
+	if got.HexString() != s.HexString() {
+		t.Errorf("reloaded as %s, want %s", got.HexString(), s.HexString())
+	}
+}
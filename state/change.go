@@ -6,9 +6,10 @@ const (
 	HueChanged
 	SaturationChanged
 	ValueChanged
+	AlphaChanged
 )
 
-const AllChanged = SelectedChanged | HueChanged | SaturationChanged | ValueChanged
+const AllChanged = SelectedChanged | HueChanged | SaturationChanged | ValueChanged | AlphaChanged
 
 // Change represents currently pending state changes
 type Change uint8
@@ -0,0 +1,28 @@
+package state
+
+// Clip is a copy of a palette color, which may be pasted into any palette
+type Clip struct {
+	pc  paletteColor
+	hex string
+}
+
+// Yank returns a Clip of the selected color, including its opacity
+func (s *State) Yank() Clip {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	ss := s.Selected()
+	pc := ss.PColor()
+	alpha := ss.alpha
+	pc.Alpha = &alpha
+	return Clip{pc: pc, hex: ss.HexString()}
+}
+
+// Paste replaces the selected color with the color and opacity of c,
+// keeping the name of the selected color
+func (s *State) Paste(c Clip) error {
+	return s.setColor(c.pc)
+}
+
+// HexString returns the hex value of the clipped color, as RRGGBBAA if
+// translucent
+func (c Clip) HexString() string { return c.hex }
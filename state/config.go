@@ -1,9 +1,17 @@
 package state
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"image/color"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/teacat/noire"
@@ -12,38 +20,119 @@ import (
 type fmtDecoder func(interface{}) (*noire.Color, error)
 
 type PaletteConfig struct {
-	Name       string         `toml:"name"`
-	Background paletteColor   `toml:"background"`
-	Colors     []paletteColor `toml:"color"`
+	Version     int            `toml:"version"`
+	Name        string         `toml:"name"`
+	Author      string         `toml:"author,omitempty"`
+	Description string         `toml:"description,omitempty"`
+	Tags        []string       `toml:"tags,omitempty"`
+	License     string         `toml:"license,omitempty"`
+	Created     time.Time      `toml:"created"`
+	Modified    time.Time      `toml:"modified"`
+	Background  paletteColor   `toml:"background"`
+	Colors      []paletteColor `toml:"color"`
+	Vim         map[string]int `toml:"vim,omitempty"` // highlight group to color index
+	Pywal       *pywalConfig   `toml:"pywal,omitempty"`
 }
 
 type paletteColor struct {
-	RGB []int     `toml:"rgb"`
-	HSV []float64 `toml:"hsv"`
-	HEX string    `toml:"hex"`
+	Name  string    `toml:"name,omitempty"`
+	RGB   []int     `toml:"rgb"`
+	HSV   []float64 `toml:"hsv"`
+	HEX   string    `toml:"hex"`
+	Alpha *float64  `toml:"alpha,omitempty"` // opacity from 0 to 100, opaque if omitted
+}
+
+// paletteCodec reads and writes a PaletteConfig in a given file format
+type paletteCodec struct {
+	decode func([]byte) (*PaletteConfig, error)
+	encode func(io.Writer, PaletteConfig) error
+	size   int // number of colors required by the format, or 0 if any
+}
+
+// supported palette file formats, by file extension
+var codecs = map[string]paletteCodec{
+	".toml": {decodeTOML, encodeTOML, 0},
+	".gpl":  {decodeGPL, encodeGPL, 0},
+	".ase":  {decodeASE, encodeASE, 0},
+	".yaml": {decodeBase16, encodeBase16, base16Count},
+	".yml":  {decodeBase16, encodeBase16, base16Count},
+	".json": {decodePywal, encodePywal, 0},
+}
+
+// codecFor returns the paletteCodec for the given path, defaulting to TOML
+func codecFor(path string) paletteCodec {
+	if c, ok := codecs[strings.ToLower(filepath.Ext(path))]; ok {
+		return c
+	}
+	return codecs[".toml"]
+}
+
+func decodeTOML(b []byte) (*PaletteConfig, error) {
+	var config PaletteConfig
+	if _, err := toml.Decode(string(b), &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func encodeTOML(w io.Writer, config PaletteConfig) error {
+	return toml.NewEncoder(w).Encode(config)
 }
 
 func (s *State) save() error {
 	log.Infof("saving state [%s]", s.path)
 
+	config := s.stampedConfig()
+
+	var buf bytes.Buffer
+	if err := codecFor(s.path).encode(&buf, config); err != nil {
+		return err
+	}
+
+	err := writeAtomic(s.path, true, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.diskSum = sha256.Sum256(buf.Bytes())
+
+	s.lock.Lock()
+	s.meta = config.readMetadata()
+	s.lock.Unlock()
+	return nil
+}
+
+// stampedConfig returns the current State as a PaletteConfig to be saved,
+// with its metadata stamped with the time of saving
+func (s *State) stampedConfig() PaletteConfig {
+	meta := s.Metadata()
+	meta.Modified = time.Now().UTC().Truncate(time.Second)
+	if meta.Created.IsZero() {
+		meta.Created = meta.Modified
+	}
+	config := s.config()
+	config.setMetadata(meta)
+	return config
+}
+
+// config returns the current State as a PaletteConfig
+func (s *State) config() PaletteConfig {
 	config := PaletteConfig{
+		Version:    paletteVersion,
 		Name:       s.Name(),
 		Background: s.background.PColor(),
+		Vim:        s.vimGroups,
+		Pywal:      s.pywal,
 	}
+	config.setMetadata(s.meta)
 
 	for _, ss := range s.sstates {
 		config.Colors = append(config.Colors, ss.PColor())
 	}
 
-	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	f.Truncate(0)
-	f.Seek(0, 0)
-
-	return toml.NewEncoder(f).Encode(config)
+	return config
 }
 
 func (s *State) load() error {
@@ -52,6 +141,7 @@ func (s *State) load() error {
 		if os.IsNotExist(err) {
 			// palette does not exist yet, will be created on save
 			s.isNew = true
+			s.fitSize()
 			return nil
 		}
 		return fmt.Errorf("failed to load palette: %s", err)
@@ -62,17 +152,30 @@ func (s *State) load() error {
 	if err != nil {
 		return err
 	}
+	s.diskSum = sha256.Sum256(b)
 
-	var config PaletteConfig
-	if _, err := toml.Decode(string(b), &config); err != nil {
+	config, err := codecFor(s.path).decode(b)
+	if err != nil {
 		return err
 	}
 
-	if len(config.Colors) > maxSubStateCount {
-		return fmt.Errorf("maximum palette size (%d) exceeded", maxSubStateCount)
+	return s.apply(config)
+}
+
+// apply replaces the name and colors of the current State with those
+// from config
+func (s *State) apply(config *PaletteConfig) error {
+	if err := migrate(config); err != nil {
+		return err
+	}
+	if problems := config.problems(); len(problems) > 0 {
+		return problems[0]
 	}
 
 	s.name = config.Name
+	s.meta = config.readMetadata()
+	s.vimGroups = config.Vim
+	s.pywal = config.Pywal
 	s.sstates = make([]*subState, len(config.Colors))
 
 	nc, err := config.Background.readColor()
@@ -81,7 +184,7 @@ func (s *State) load() error {
 			return fmt.Errorf("[background] %s", err)
 		}
 	} else {
-		s.background = &subState{nc, nc.Hue()}
+		s.background = newSubState(nc)
 		log.Debugf("loaded background from %s", s.path)
 	}
 
@@ -90,7 +193,11 @@ func (s *State) load() error {
 		if err != nil {
 			return fmt.Errorf("[color%d] %s", n, err)
 		}
-		s.sstates[n] = &subState{nc, nc.Hue()}
+		s.sstates[n] = newSubState(nc)
+		s.sstates[n].name = pc.Name
+		if alpha, ok, _ := pc.readAlpha(); ok {
+			s.sstates[n].alpha = alpha
+		}
 		log.Debugf("loaded substate [%d] from %s", n, s.path)
 	}
 
@@ -98,6 +205,12 @@ func (s *State) load() error {
 	return nil
 }
 
+// rgbPaletteColor returns a paletteColor for the given color, ignoring alpha
+func rgbPaletteColor(c color.Color) paletteColor {
+	r, g, b, _ := c.RGBA()
+	return paletteColor{RGB: []int{int(r >> 8), int(g >> 8), int(b >> 8)}}
+}
+
 func (pc *paletteColor) readColor() (*noire.Color, error) {
 	switch {
 	case len(pc.RGB) != 0:
@@ -111,12 +224,59 @@ func (pc *paletteColor) readColor() (*noire.Color, error) {
 		}
 		return noire.NewHSV(pc.HSV[0], pc.HSV[1], pc.HSV[2]), nil
 	case len(pc.HEX) != 0:
-		return noire.NewHex(pc.HEX), nil
+		rgba, err := parseHex(pc.HEX)
+		if err != nil {
+			return nil, err
+		}
+		return noire.NewRGB(float64(rgba[0]), float64(rgba[1]), float64(rgba[2])), nil
 	default:
 		return nil, fmt.Errorf("missing definition")
 	}
 }
 
+// parseHex parses a hex color in RGB, RRGGBB or RRGGBBAA form, with an
+// optional leading "#". Alpha is 255 where not given.
+func parseHex(s string) (rgba [4]int, err error) {
+	digits := strings.TrimPrefix(s, "#")
+	switch len(digits) {
+	case 3:
+		// expand short form, e.g. F73 -> FF7733
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	case 6, 8:
+	default:
+		return rgba, fmt.Errorf("malformed HEX %q (expected 3, 6 or 8 digits)", s)
+	}
+
+	rgba[3] = 255
+	for n := 0; n < len(digits)/2; n++ {
+		v, err := strconv.ParseUint(digits[n*2:n*2+2], 16, 8)
+		if err != nil {
+			return rgba, fmt.Errorf("malformed HEX %q (invalid digits %q)", s, digits[n*2:n*2+2])
+		}
+		rgba[n] = int(v)
+	}
+	return rgba, nil
+}
+
+// readAlpha returns the opacity given by the alpha value, or else by an
+// RRGGBBAA hex value. ok is false if neither specify opacity.
+func (pc *paletteColor) readAlpha() (alpha float64, ok bool, err error) {
+	if pc.Alpha != nil {
+		if *pc.Alpha < 0 || *pc.Alpha > opaque {
+			return 0, false, fmt.Errorf("malformed alpha (must be between 0 and %.0f)", opaque)
+		}
+		return *pc.Alpha, true, nil
+	}
+	if len(strings.TrimPrefix(pc.HEX, "#")) == 8 {
+		rgba, err := parseHex(pc.HEX)
+		if err != nil {
+			return 0, false, err
+		}
+		return float64(rgba[3]) / 255 * opaque, true, nil
+	}
+	return 0, false, nil
+}
+
 func (pc *paletteColor) validRGB() error {
 	if len(pc.RGB) > 3 {
 		return fmt.Errorf("malformed RGB (too many values)")
@@ -139,8 +299,8 @@ func (pc *paletteColor) validHSV() error {
 	if len(pc.HSV) < 3 {
 		return fmt.Errorf("malformed HSV (too few values)")
 	}
-	if pc.HSV[0] < 0 || pc.HSV[0] > 359 {
-		return fmt.Errorf("malformed HSV (hue out of 0-359 bounds)")
+	if pc.HSV[0] < 0 || pc.HSV[0] >= 360 {
+		return fmt.Errorf("malformed HSV (hue out of 0-360 bounds)")
 	}
 	if pc.HSV[1] < 0 || pc.HSV[1] > 100 {
 		return fmt.Errorf("malformed HSV (saturation out of 0-100 bounds)")
@@ -6,9 +6,14 @@ import (
 	"regexp"
 )
 
-var DefaultPalettePath = defaultPalettePath()
+var (
+	DefaultPalettePath = defaultConfigFile("default.toml")
+	DefaultKeymapPath  = defaultConfigFile("keys.toml")
+)
 
-func defaultPalettePath() string {
+// return path to the named file in the config base dir, creating the dir
+// if needed
+func defaultConfigFile(name string) string {
 	path, err := getConfigPath()
 	if err != nil {
 		panic(err)
@@ -16,7 +21,7 @@ func defaultPalettePath() string {
 	if err := ensureDir(path); err != nil {
 		panic(err)
 	}
-	return fmt.Sprintf("%s/default.toml", path)
+	return fmt.Sprintf("%s/%s", path, name)
 }
 
 // attempt create dir if not exist
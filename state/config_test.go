@@ -0,0 +1,173 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    [4]int
+		wantErr bool
+	}{
+		{in: "FF7733", want: [4]int{0xff, 0x77, 0x33, 255}},
+		{in: "#ff7733", want: [4]int{0xff, 0x77, 0x33, 255}},
+		{in: "F73", want: [4]int{0xff, 0x77, 0x33, 255}},
+		{in: "#f73", want: [4]int{0xff, 0x77, 0x33, 255}},
+		{in: "FF773380", want: [4]int{0xff, 0x77, 0x33, 0x80}},
+		{in: "#FF773300", want: [4]int{0xff, 0x77, 0x33, 0}},
+		{in: "FF77G3", wantErr: true},
+		{in: "#", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "FF773", wantErr: true},
+		{in: "FF77333", wantErr: true},
+		{in: "F7G", wantErr: true},
+		{in: "##FF7733", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseHex(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseHex(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseHex(%q) returned error: %s", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseHex(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReadColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		pc      paletteColor
+		want    [3]float64
+		wantErr bool
+	}{
+		{name: "rgb", pc: paletteColor{RGB: []int{255, 119, 51}}, want: [3]float64{255, 119, 51}},
+		{name: "hex", pc: paletteColor{HEX: "#FF7733"}, want: [3]float64{255, 119, 51}},
+		{name: "short hex", pc: paletteColor{HEX: "f73"}, want: [3]float64{255, 119, 51}},
+		{name: "alpha hex", pc: paletteColor{HEX: "FF773380"}, want: [3]float64{255, 119, 51}},
+		{name: "malformed hex", pc: paletteColor{HEX: "FF77G3"}, wantErr: true},
+		{name: "bare hash", pc: paletteColor{HEX: "#"}, wantErr: true},
+		{name: "5 digit hex", pc: paletteColor{HEX: "FF773"}, wantErr: true},
+		{name: "7 digit hex", pc: paletteColor{HEX: "FF77333"}, wantErr: true},
+		{name: "rgb over range", pc: paletteColor{RGB: []int{256, 0, 0}}, wantErr: true},
+		{name: "rgb under range", pc: paletteColor{RGB: []int{0, -1, 0}}, wantErr: true},
+		{name: "rgb too few", pc: paletteColor{RGB: []int{0, 0}}, wantErr: true},
+		{name: "rgb too many", pc: paletteColor{RGB: []int{0, 0, 0, 0}}, wantErr: true},
+		{name: "hue over range", pc: paletteColor{HSV: []float64{360, 50, 50}}, wantErr: true},
+		{name: "saturation over range", pc: paletteColor{HSV: []float64{0, 101, 50}}, wantErr: true},
+		{name: "value under range", pc: paletteColor{HSV: []float64{0, 50, -1}}, wantErr: true},
+		{name: "hsv too few", pc: paletteColor{HSV: []float64{0, 50}}, wantErr: true},
+		{name: "missing", pc: paletteColor{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		nc, err := tt.pc.readColor()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: readColor() succeeded, want error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: readColor() returned error: %s", tt.name, err)
+			continue
+		}
+		r, g, b := nc.RGB()
+		if got := [3]float64{r, g, b}; got != tt.want {
+			t.Errorf("%s: readColor() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadColorHSV(t *testing.T) {
+	for _, hue := range []float64{0, 359, 359.76, 359.999} {
+		pc := paletteColor{HSV: []float64{hue, 100, 100}}
+		if _, err := pc.readColor(); err != nil {
+			t.Errorf("readColor() returned error for hue %v: %s", hue, err)
+		}
+	}
+}
+
+func TestReadAlpha(t *testing.T) {
+	half := 50.0
+	over := 101.0
+	tests := []struct {
+		name    string
+		pc      paletteColor
+		want    float64
+		wantOk  bool
+		wantErr bool
+	}{
+		{name: "opaque hex", pc: paletteColor{HEX: "FF7733"}},
+		{name: "alpha hex", pc: paletteColor{HEX: "#FF773300"}, want: 0, wantOk: true},
+		{name: "alpha hex full", pc: paletteColor{HEX: "FF7733FF"}, want: opaque, wantOk: true},
+		{name: "alpha value", pc: paletteColor{HEX: "FF773300", Alpha: &half}, want: half, wantOk: true},
+		{name: "alpha over range", pc: paletteColor{Alpha: &over}, wantErr: true},
+		{name: "malformed alpha hex", pc: paletteColor{HEX: "FF7733GG"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, ok, err := tt.pc.readAlpha()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: readAlpha() succeeded, want error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: readAlpha() returned error: %s", tt.name, err)
+			continue
+		}
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("%s: readAlpha() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+// roundTrip encodes config with codec c and decodes the result
+func roundTrip(t *testing.T, c paletteCodec, config PaletteConfig) *PaletteConfig {
+	var buf bytes.Buffer
+	if err := c.encode(&buf, config); err != nil {
+		t.Fatalf("encode: %s", err)
+	}
+	got, err := c.decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decode: %s\n%s", err, buf.String())
+	}
+	return got
+}
+
+// checkRGB reports any color of got differing in RGB value from want
+func checkRGB(t *testing.T, got, want PaletteConfig) {
+	check := func(label string, g, w paletteColor) {
+		gc, err := g.readColor()
+		if err != nil {
+			t.Errorf("%s: %s", label, err)
+			return
+		}
+		wc, _ := w.readColor()
+		gr, gg, gb := gc.RGB()
+		wr, wg, wb := wc.RGB()
+		if gr != wr || gg != wg || gb != wb {
+			t.Errorf("%s = %v %v %v, want %v %v %v", label, gr, gg, gb, wr, wg, wb)
+		}
+	}
+	check("background", got.Background, want.Background)
+	if len(got.Colors) != len(want.Colors) {
+		t.Fatalf("got %d colors, want %d", len(got.Colors), len(want.Colors))
+	}
+	for n := range want.Colors {
+		check(fmt.Sprintf("color %d", n), got.Colors[n], want.Colors[n])
+	}
+}
@@ -0,0 +1,134 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CSSStyle selects how color values are written in stylesheet outputs
+type CSSStyle string
+
+const (
+	CSSHex CSSStyle = "hex"
+	CSSRGB CSSStyle = "rgb"
+	CSSHSL CSSStyle = "hsl"
+)
+
+// ParseCSSStyle returns the CSSStyle for the given name
+func ParseCSSStyle(name string) (CSSStyle, error) {
+	switch style := CSSStyle(strings.ToLower(strings.TrimSpace(name))); style {
+	case CSSHex, CSSRGB, CSSHSL:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unknown css style \"%s\"", name)
+	}
+}
+
+// CSSString returns the current State as CSS custom properties on :root
+func (s *State) CSSString(style CSSStyle) string {
+	var buf bytes.Buffer
+	s.writeCSSHeader(&buf)
+	buf.WriteString(":root {\n")
+	s.eachCSSVar(style, func(name, value string) {
+		fmt.Fprintf(&buf, "  --%s: %s;\n", name, value)
+	})
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// SCSSString returns the current State as SCSS variables
+func (s *State) SCSSString(style CSSStyle) string {
+	var buf bytes.Buffer
+	s.writeCSSHeader(&buf)
+	s.eachCSSVar(style, func(name, value string) {
+		fmt.Fprintf(&buf, "$%s: %s;\n", name, value)
+	})
+	return buf.String()
+}
+
+// LessString returns the current State as Less variables
+func (s *State) LessString(style CSSStyle) string {
+	var buf bytes.Buffer
+	s.writeCSSHeader(&buf)
+	s.eachCSSVar(style, func(name, value string) {
+		fmt.Fprintf(&buf, "@%s: %s;\n", name, value)
+	})
+	return buf.String()
+}
+
+// writeCSSHeader writes a comment naming the palette, with its metadata.
+// Block comments are valid in CSS, SCSS and Less alike.
+func (s *State) writeCSSHeader(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "/*\n * Name: %s\n", cssComment(s.Name()))
+	s.eachMetaLine(func(line string) {
+		fmt.Fprintf(buf, " * %s\n", cssComment(line))
+	})
+	buf.WriteString(" * Generated by tcolors\n */\n\n")
+}
+
+// cssComment returns s made safe for inclusion in a block comment
+func cssComment(s string) string {
+	return strings.Replace(s, "*/", "* /", -1)
+}
+
+// eachCSSVar calls fn with the variable name and value of the background and
+// each palette color, prefixed with the palette name
+func (s *State) eachCSSVar(style CSSStyle, fn func(string, string)) {
+	prefix := slugify(s.Name())
+	if prefix != "" {
+		prefix += "-"
+	}
+
+	s.eachNamedColor(func(name string, ss *subState) {
+		fn(prefix+name, cssValue(ss, style))
+	})
+}
+
+// eachNamedColor calls fn with the background and each palette color, along
+// with their names for use in exported variables
+func (s *State) eachNamedColor(fn func(string, *subState)) {
+	fn("bg", s.background)
+	for n, ss := range s.sstates {
+		fn(fmt.Sprintf("color-%d", n), ss)
+	}
+}
+
+func cssValue(ss *subState, style CSSStyle) string {
+	switch style {
+	case CSSRGB:
+		r, g, b := ss.RGB()
+		if !ss.Opaque() {
+			return fmt.Sprintf("rgba(%.0f, %.0f, %.0f, %.2f)", r, g, b, ss.Alpha()/opaque)
+		}
+		return fmt.Sprintf("rgb(%.0f, %.0f, %.0f)", r, g, b)
+	case CSSHSL:
+		h, s, l := ss.HSL()
+		if !ss.Opaque() {
+			return fmt.Sprintf("hsla(%.0f, %.0f%%, %.0f%%, %.2f)", h, s, l, ss.Alpha()/opaque)
+		}
+		return fmt.Sprintf("hsl(%.0f, %.0f%%, %.0f%%)", h, s, l)
+	default:
+		return "#" + strings.ToLower(ss.HexString())
+	}
+}
+
+// slugify returns s lowercased, with each run of non-alphanumeric
+// characters replaced by a single hyphen
+func slugify(s string) string {
+	var buf bytes.Buffer
+	sep := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if sep && buf.Len() > 0 {
+				buf.WriteRune('-')
+			}
+			buf.WriteRune(r)
+			sep = false
+			continue
+		}
+		sep = true
+	}
+	return buf.String()
+}
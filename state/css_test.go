@@ -0,0 +1,138 @@
+package state
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSSStyle(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    CSSStyle
+		wantErr bool
+	}{
+		{in: "hex", want: CSSHex},
+		{in: "RGB", want: CSSRGB},
+		{in: " hsl ", want: CSSHSL},
+		{in: "hsv", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCSSStyle(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseCSSStyle(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCSSStyle(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Solarized Dark", "solarized-dark"},
+		{"  --Mixed__Case 2--", "mixed-case-2"},
+		{"ünïcödé", "ünïcödé"},
+		{"!!!", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSSValue(t *testing.T) {
+	s := testState(t, []int{0, 0, 0}, []int{255, 0, 0})
+	ss := s.sstates[0]
+
+	tests := []struct {
+		style CSSStyle
+		alpha float64
+		want  string
+	}{
+		{style: CSSHex, alpha: opaque, want: "#ff0000"},
+		{style: CSSHex, alpha: 50, want: "#ff000080"},
+		{style: CSSRGB, alpha: opaque, want: "rgb(255, 0, 0)"},
+		{style: CSSRGB, alpha: 50, want: "rgba(255, 0, 0, 0.50)"},
+		{style: CSSHSL, alpha: opaque, want: "hsl(0, 100%, 50%)"},
+		{style: CSSHSL, alpha: 50, want: "hsla(0, 100%, 50%, 0.50)"},
+	}
+
+	for _, tt := range tests {
+		ss.alpha = tt.alpha
+		if got := cssValue(ss, tt.style); got != tt.want {
+			t.Errorf("cssValue(%s, alpha %v) = %q, want %q", tt.style, tt.alpha, got, tt.want)
+		}
+	}
+}
+
+func TestCSSOutputs(t *testing.T) {
+	s := testState(t, []int{0, 0, 0}, []int{255, 0, 0}, []int{0, 0, 255})
+	s.name = "My Palette"
+
+	tests := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{"css", s.CSSString(CSSHex), []string{
+			":root {\n",
+			"  --my-palette-bg: #000000;\n",
+			"  --my-palette-color-0: #ff0000;\n",
+			"  --my-palette-color-1: #0000ff;\n",
+			"}\n",
+		}},
+		{"scss", s.SCSSString(CSSHex), []string{
+			"$my-palette-bg: #000000;\n",
+			"$my-palette-color-0: #ff0000;\n",
+			"$my-palette-color-1: #0000ff;\n",
+		}},
+		{"less", s.LessString(CSSRGB), []string{
+			"@my-palette-bg: rgb(0, 0, 0);\n",
+			"@my-palette-color-0: rgb(255, 0, 0);\n",
+			"@my-palette-color-1: rgb(0, 0, 255);\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		// variables are written in palette order
+		rest := tt.out
+		for _, line := range tt.want {
+			i := strings.Index(rest, line)
+			if i < 0 {
+				t.Errorf("%s: missing or misordered %q in\n%s", tt.name, line, tt.out)
+				break
+			}
+			rest = rest[i+len(line):]
+		}
+	}
+}
+
+func TestCSSHeader(t *testing.T) {
+	s := testState(t, []int{0, 0, 0}, []int{255, 0, 0})
+	s.name = "My Palette"
+	s.meta = Metadata{Author: "A. Author", Description: "ends */ early", Tags: []string{"dark"}}
+
+	for name, out := range map[string]string{
+		"css":  s.CSSString(CSSHex),
+		"scss": s.SCSSString(CSSHex),
+		"less": s.LessString(CSSHex),
+	} {
+		want := "/*\n * Name: My Palette\n * Author: A. Author\n * Description: ends * / early\n * Tags: dark\n"
+		if !strings.HasPrefix(out, want) {
+			t.Errorf("%s: got header\n%s\nwant prefix\n%s", name, out, want)
+		}
+		if strings.Count(out, "*/") != 1 {
+			t.Errorf("%s: header comment not closed exactly once\n%s", name, out)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package state
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+)
+
+// DiskChanged returns whether the palette file has been modified by another
+// program since it was last loaded or saved
+func (s *State) DiskChanged() bool {
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return false
+	}
+	return sha256.Sum256(b) != s.diskSum
+}
+
+// IgnoreDiskChange accepts the current palette file contents as last loaded,
+// keeping the current State. The palette file is overwritten on next save.
+func (s *State) IgnoreDiskChange() {
+	if b, err := ioutil.ReadFile(s.path); err == nil {
+		s.diskSum = sha256.Sum256(b)
+	}
+}
+
+// Reload replaces the current State with the palette file contents,
+// discarding any unsaved changes. The selected position is kept where
+// possible.
+func (s *State) Reload() error {
+	other, err := read(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	pos := s.pos
+	s.replace(other)
+	if pos < s.Len() {
+		s.pos = pos
+	}
+	s.diskSum = other.diskSum
+	s.dirty = false
+	return nil
+}
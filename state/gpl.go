@@ -0,0 +1,145 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	gplHeader         = "GIMP Palette"
+	gplBackgroundName = "Background"
+	gplUntitledName   = "Untitled"
+)
+
+// GPLString returns the current State as a GIMP/Inkscape palette
+func (s *State) GPLString() string {
+	var buf bytes.Buffer
+	encodeGPL(&buf, s.config())
+	return buf.String()
+}
+
+// encodeGPL writes config as a GIMP palette. The background is written as
+// the first row, named "Background", and metadata as header comments
+func encodeGPL(w io.Writer, config PaletteConfig) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", gplHeader)
+	fmt.Fprintf(&buf, "Name: %s\n", config.Name)
+	fmt.Fprintf(&buf, "Columns: %d\n", len(config.Colors)+1)
+	fmt.Fprintf(&buf, "#\n")
+	if fields := config.readMetadata().Fields(); len(fields) > 0 {
+		for _, f := range fields {
+			fmt.Fprintf(&buf, "# %s: %s\n", f.Label, f.Value)
+		}
+		fmt.Fprintf(&buf, "#\n")
+	}
+
+	writeGPLRow(&buf, config.Background, gplBackgroundName)
+	for _, pc := range config.Colors {
+		name := pc.Name
+		if name == "" {
+			name = gplUntitledName
+		}
+		writeGPLRow(&buf, pc, name)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeGPLRow(buf *bytes.Buffer, pc paletteColor, name string) {
+	fmt.Fprintf(buf, "%3d %3d %3d\t%s\n", pc.RGB[0], pc.RGB[1], pc.RGB[2], name)
+}
+
+// decodeGPL reads a GIMP palette into a PaletteConfig. A row named
+// "Background" is used as the palette background, if present, and metadata
+// is read from "# Label: value" comments
+func decodeGPL(b []byte) (*PaletteConfig, error) {
+	config := PaletteConfig{Version: paletteVersion}
+	var hasBackground bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	lineN := 0
+	for scanner.Scan() {
+		lineN++
+		line := strings.TrimSpace(scanner.Text())
+
+		if lineN == 1 {
+			if strings.TrimPrefix(line, "\ufeff") != gplHeader {
+				return nil, fmt.Errorf("line 1: missing \"%s\" header", gplHeader)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			// metadata is written as "# Label: value" comments. Comments
+			// are free-form, so unreadable values are skipped
+			if i := strings.Index(line, ":"); i > 0 {
+				label := strings.TrimSpace(line[1:i])
+				if err := config.setField(label, strings.TrimSpace(line[i+1:])); err != nil {
+					log.Warningf("gpl line %d: ignoring %s", lineN, err)
+				}
+			}
+			continue
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "Name:"):
+			config.Name = strings.TrimSpace(strings.TrimPrefix(line, "Name:"))
+			continue
+		case strings.HasPrefix(line, "Columns:"):
+			continue
+		}
+
+		pc, err := readGPLRow(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineN, err)
+		}
+
+		if !hasBackground && strings.EqualFold(pc.Name, gplBackgroundName) {
+			pc.Name = ""
+			config.Background = pc
+			hasBackground = true
+			continue
+		}
+		config.Colors = append(config.Colors, pc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lineN == 0 {
+		return nil, fmt.Errorf("line 1: missing \"%s\" header", gplHeader)
+	}
+
+	return &config, nil
+}
+
+// readGPLRow parses a single "R G B name" palette row
+func readGPLRow(line string) (pc paletteColor, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return pc, fmt.Errorf("malformed color row (too few values)")
+	}
+
+	for _, field := range fields[:3] {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return pc, fmt.Errorf("malformed color row (invalid value \"%s\")", field)
+		}
+		pc.RGB = append(pc.RGB, n)
+	}
+
+	if err := pc.validRGB(); err != nil {
+		return pc, err
+	}
+
+	pc.Name = strings.Join(fields[3:], " ")
+	if pc.Name == gplUntitledName {
+		pc.Name = ""
+	}
+
+	return pc, nil
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGPLRoundTrip(t *testing.T) {
+	s := testState(t, []int{10, 20, 30}, []int{255, 0, 0}, []int{0, 255, 0}, []int{1, 2, 3})
+	s.name = "Round Trip"
+	s.sstates[0].name = "red"
+	s.sstates[1].name = "light green"
+	s.meta = Metadata{
+		Author:   "A. Author",
+		Tags:     []string{"dark", "warm"},
+		License:  "MIT",
+		Created:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Modified: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	want := s.config()
+
+	got := roundTrip(t, codecs[".gpl"], want)
+	checkRGB(t, *got, want)
+	if got.Name != want.Name {
+		t.Errorf("name = %q, want %q", got.Name, want.Name)
+	}
+	for n, name := range []string{"red", "light green", ""} {
+		if got.Colors[n].Name != name {
+			t.Errorf("color %d name = %q, want %q", n, got.Colors[n].Name, name)
+		}
+	}
+	if m := got.readMetadata(); !reflect.DeepEqual(m, s.meta) {
+		t.Errorf("metadata = %+v, want %+v", m, s.meta)
+	}
+}
+
+func TestDecodeGPL(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantBg  []int
+		wantLen int
+		wantErr string
+	}{
+		{name: "plain", in: "GIMP Palette\n255 0 0 red\n0 0 255\n", wantLen: 2},
+		{name: "bom", in: "\ufeffGIMP Palette\n255 0 0\n", wantLen: 1},
+		{name: "background", in: "GIMP Palette\n1 2 3 Background\n255 0 0\n", wantBg: []int{1, 2, 3}, wantLen: 1},
+		{name: "comments", in: "GIMP Palette\nName: x\nColumns: 4\n#\n# free text\n\n255 0 0\n", wantLen: 1},
+		{name: "empty", in: "", wantErr: "line 1: missing"},
+		{name: "missing header", in: "255 0 0\n", wantErr: "line 1: missing"},
+		{name: "too few values", in: "GIMP Palette\n255 0\n", wantErr: "line 2: malformed color row (too few"},
+		{name: "invalid value", in: "GIMP Palette\n255 0 x\n", wantErr: "line 2: malformed color row (invalid"},
+		{name: "out of range", in: "GIMP Palette\n0 0 0\n256 0 0\n", wantErr: "line 3:"},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeGPL([]byte(tt.in))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: decodeGPL() returned error: %s", tt.name, err)
+			continue
+		}
+		if len(got.Colors) != tt.wantLen {
+			t.Errorf("%s: got %d colors, want %d", tt.name, len(got.Colors), tt.wantLen)
+		}
+		if !reflect.DeepEqual(got.Background.RGB, tt.wantBg) {
+			t.Errorf("%s: background = %v, want %v", tt.name, got.Background.RGB, tt.wantBg)
+		}
+	}
+}
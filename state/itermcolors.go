@@ -0,0 +1,57 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	itermHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`
+	itermFooter = `</dict>
+</plist>
+`
+	itermMaxAnsi = 16
+)
+
+// ITermString returns the current State as an iTerm2 .itermcolors property
+// list. Palette colors are mapped in order to the first 16 ANSI colors, with
+// the foreground and cursor set to the color contrasting most with the
+// background.
+func (s *State) ITermString() string {
+	var buf bytes.Buffer
+	buf.WriteString(itermHeader)
+
+	for n, ss := range s.sstates {
+		if n >= itermMaxAnsi {
+			break
+		}
+		writeITermColor(&buf, fmt.Sprintf("Ansi %d Color", n), ss)
+	}
+
+	fg := s.foreground()
+	writeITermColor(&buf, "Background Color", s.background)
+	writeITermColor(&buf, "Cursor Color", fg)
+	writeITermColor(&buf, "Foreground Color", fg)
+
+	buf.WriteString(itermFooter)
+	return buf.String()
+}
+
+func writeITermColor(buf *bytes.Buffer, key string, ss *subState) {
+	r, g, b := ss.RGB()
+	fmt.Fprintf(buf, "\t<key>%s</key>\n\t<dict>\n", key)
+	writeITermComponent(buf, "Alpha Component", ss.Alpha()/opaque)
+	writeITermComponent(buf, "Blue Component", b/255)
+	fmt.Fprintf(buf, "\t\t<key>Color Space</key>\n\t\t<string>sRGB</string>\n")
+	writeITermComponent(buf, "Green Component", g/255)
+	writeITermComponent(buf, "Red Component", r/255)
+	fmt.Fprintf(buf, "\t</dict>\n")
+}
+
+func writeITermComponent(buf *bytes.Buffer, key string, n float64) {
+	fmt.Fprintf(buf, "\t\t<key>%s</key>\n\t\t<real>%.8f</real>\n", key, n)
+}
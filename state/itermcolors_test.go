@@ -0,0 +1,150 @@
+package state
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// plistItem is a decoded plist element, with any nested elements
+type plistItem struct {
+	XMLName xml.Name
+	Value   string      `xml:",chardata"`
+	Items   []plistItem `xml:",any"`
+}
+
+// plist is a decoded property list, holding a <dict> of alternating keys
+// and values
+type plist struct {
+	Dict struct {
+		Items []plistItem `xml:",any"`
+	} `xml:"dict"`
+}
+
+// decodeITerm returns the colors of an .itermcolors property list by key,
+// each as a map of component key to value
+func decodeITerm(t *testing.T, s string) map[string]map[string]string {
+	var p plist
+	if err := xml.Unmarshal([]byte(s), &p); err != nil {
+		t.Fatalf("failed to decode itermcolors: %s", err)
+	}
+
+	colors := make(map[string]map[string]string)
+	items := p.Dict.Items
+	if len(items)%2 != 0 {
+		t.Fatalf("odd number of plist dict items: %d", len(items))
+	}
+	for n := 0; n < len(items); n += 2 {
+		key, val := items[n], items[n+1]
+		if key.XMLName.Local != "key" || val.XMLName.Local != "dict" {
+			t.Fatalf("unexpected plist items <%s>, <%s>", key.XMLName.Local, val.XMLName.Local)
+		}
+		components := make(map[string]string)
+		for i := 0; i+1 < len(val.Items); i += 2 {
+			components[val.Items[i].Value] = strings.TrimSpace(val.Items[i+1].Value)
+		}
+		colors[key.Value] = components
+	}
+	return colors
+}
+
+func testState(t *testing.T, bg []int, colors ...[]int) *State {
+	config := &PaletteConfig{Background: paletteColor{RGB: bg}}
+	for _, c := range colors {
+		config.Colors = append(config.Colors, paletteColor{RGB: c})
+	}
+	s := New()
+	if err := s.apply(config); err != nil {
+		t.Fatalf("failed to apply config: %s", err)
+	}
+	return s
+}
+
+func checkITermColor(t *testing.T, key string, components map[string]string, rgb []int, alpha float64) {
+	want := map[string]float64{
+		"Red Component":   float64(rgb[0]) / 255,
+		"Green Component": float64(rgb[1]) / 255,
+		"Blue Component":  float64(rgb[2]) / 255,
+		"Alpha Component": alpha,
+	}
+	for name, v := range want {
+		s, ok := components[name]
+		if !ok {
+			t.Errorf("%s: missing %s", key, name)
+			continue
+		}
+		got, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			t.Errorf("%s: malformed %s %q", key, name, s)
+			continue
+		}
+		if math.Abs(got-v) > 1e-6 {
+			t.Errorf("%s: %s = %v, want %v", key, name, got, v)
+		}
+	}
+	if cs := components["Color Space"]; cs != "sRGB" {
+		t.Errorf("%s: Color Space = %q, want sRGB", key, cs)
+	}
+}
+
+func TestITermString(t *testing.T) {
+	bg := []int{0, 0, 0}
+	colors := [][]int{
+		{255, 0, 0},
+		{0, 128, 0},
+		{10, 20, 255},
+		{255, 255, 255},
+	}
+	s := testState(t, bg, colors...)
+	s.sstates[1].alpha = 50
+
+	got := decodeITerm(t, s.ITermString())
+
+	for n, rgb := range colors {
+		key := fmt.Sprintf("Ansi %d Color", n)
+		components, ok := got[key]
+		if !ok {
+			t.Errorf("missing %s", key)
+			continue
+		}
+		alpha := 1.0
+		if n == 1 {
+			alpha = 0.5
+		}
+		checkITermColor(t, key, components, rgb, alpha)
+	}
+	if _, ok := got[fmt.Sprintf("Ansi %d Color", len(colors))]; ok {
+		t.Errorf("unexpected Ansi %d Color", len(colors))
+	}
+
+	for _, key := range []string{"Background Color", "Foreground Color", "Cursor Color"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing %s", key)
+		}
+	}
+	checkITermColor(t, "Background Color", got["Background Color"], bg, 1)
+	// white contrasts most with the black background
+	checkITermColor(t, "Foreground Color", got["Foreground Color"], colors[3], 1)
+	checkITermColor(t, "Cursor Color", got["Cursor Color"], colors[3], 1)
+}
+
+func TestITermStringMaxAnsi(t *testing.T) {
+	var colors [][]int
+	for n := 0; n < maxSubStateCount; n++ {
+		colors = append(colors, []int{n, n, n})
+	}
+	s := testState(t, []int{0, 0, 0}, colors...)
+
+	got := decodeITerm(t, s.ITermString())
+	for n := 0; n < itermMaxAnsi; n++ {
+		if _, ok := got[fmt.Sprintf("Ansi %d Color", n)]; !ok {
+			t.Errorf("missing Ansi %d Color", n)
+		}
+	}
+	if len(got) != itermMaxAnsi+3 {
+		t.Errorf("got %d colors, want %d", len(got), itermMaxAnsi+3)
+	}
+}
@@ -0,0 +1,194 @@
+package state
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// LibraryEntry is a single palette file in the palette library
+type LibraryEntry struct {
+	Name       string // file name
+	Path       string
+	Background tcell.Color
+	Swatches   []tcell.Color
+	Err        error // set if the palette could not be read
+}
+
+// LibraryDir returns the palette library directory, where the default
+// palette is stored
+func LibraryDir() string { return filepath.Dir(DefaultPalettePath) }
+
+// Library returns an entry for each palette file in dir, sorted by name
+func Library(dir string) ([]LibraryEntry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read palette library: %s", err)
+	}
+
+	var entries []LibraryEntry
+	for _, fi := range files {
+		name := fi.Name()
+		path := filepath.Join(dir, name)
+		if fi.IsDir() || strings.HasPrefix(name, ".") || !isPaletteFile(path) {
+			continue
+		}
+		if path == DefaultKeymapPath {
+			continue
+		}
+
+		entry := LibraryEntry{Name: name, Path: path}
+		s := NewDefault()
+		s.path = path
+		if err := s.load(); err != nil {
+			entry.Err = err
+		} else {
+			entry.Background = s.Background()
+			entry.Swatches = s.Swatches()
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+	return entries, nil
+}
+
+// isPaletteFile returns whether path has the extension of a supported
+// palette format
+func isPaletteFile(path string) bool {
+	_, ok := codecs[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// LibraryPath returns the path in dir for a palette with the given file
+// name, adding a .toml extension if the name has no supported extension
+func LibraryPath(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsRune(name, filepath.Separator) {
+		return "", fmt.Errorf("invalid palette name %q", name)
+	}
+	if !isPaletteFile(name) {
+		name += ".toml"
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// CreatePalette writes a new palette of default colors to path
+func CreatePalette(path string) error {
+	if err := checkNotExist(path); err != nil {
+		return err
+	}
+	s := NewDefault()
+	s.path = path
+	if err := s.save(); err != nil {
+		return fmt.Errorf("failed to create palette: %s", err)
+	}
+	return nil
+}
+
+// DuplicatePalette writes a copy of the palette at src to dst, named for
+// dst and with new creation and modification times
+func DuplicatePalette(src, dst string) error {
+	if err := checkNotExist(dst); err != nil {
+		return err
+	}
+	s, err := read(src)
+	if err != nil {
+		return err
+	}
+	s.path = dst
+	s.name = ""
+	s.meta.Created, s.meta.Modified = time.Time{}, time.Time{}
+	if err := s.save(); err != nil {
+		return fmt.Errorf("failed to duplicate palette: %s", err)
+	}
+	return nil
+}
+
+// RenamePalette moves the palette at src, along with its backups and any
+// recovery file, to dst. The palette file format may not be changed.
+func RenamePalette(src, dst string) error {
+	if err := checkNotExist(dst); err != nil {
+		return err
+	}
+	if ext := filepath.Ext(src); !strings.EqualFold(ext, filepath.Ext(dst)) {
+		return fmt.Errorf("%s must keep its %s extension (duplicate to change format)", filepath.Base(src), ext)
+	}
+	if err := checkUnlocked(src); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to rename palette: %s", err)
+	}
+
+	moves := [][2]string{{recoveryPath(src), recoveryPath(dst)}}
+	for n := 1; n <= BackupCount; n++ {
+		moves = append(moves, [2]string{backupPath(src, n), backupPath(dst, n)})
+	}
+	for _, m := range moves {
+		if err := os.Rename(m[0], m[1]); err != nil && !os.IsNotExist(err) {
+			log.Warningf("failed to move %s: %s", m[0], err)
+		}
+	}
+	os.Remove(lockPath(src))
+	return nil
+}
+
+// DeletePalette removes the palette at path and any recovery file. The
+// palette is first rotated into its backups, from which it may be restored.
+func DeletePalette(path string) error {
+	if err := checkUnlocked(path); err != nil {
+		return err
+	}
+	if err := rotateBackups(path); err != nil {
+		return fmt.Errorf("failed to back up palette: %s", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete palette: %s", err)
+	}
+	os.Remove(recoveryPath(path))
+	os.Remove(lockPath(path))
+	return nil
+}
+
+func checkNotExist(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Base(path))
+	}
+	return nil
+}
+
+// checkUnlocked returns an error if the palette at path is locked, being
+// open in another tcolors process or another tab of this one
+func checkUnlocked(path string) error {
+	f, err := os.Open(lockPath(path))
+	if err != nil {
+		// no lock file, or none that can be held
+		return nil
+	}
+	defer f.Close()
+
+	ok, err := tryLock(f)
+	if err != nil {
+		return nil
+	}
+	if ok {
+		unlock(f)
+		return nil
+	}
+	b, _ := ioutil.ReadAll(f)
+	pid := strings.TrimSpace(string(b))
+	if pid == strconv.Itoa(os.Getpid()) {
+		return fmt.Errorf("%s is open in another tab", filepath.Base(path))
+	}
+	return fmt.Errorf("%s is open in another tcolors process (pid %s)", filepath.Base(path), pid)
+}
@@ -0,0 +1,104 @@
+package state
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// lockPath returns the path of the advisory lock file for the palette at
+// path
+func lockPath(path string) string { return path + ".lock" }
+
+// acquireLock attempts to take the advisory lock on the palette file,
+// making state read-only if the lock is held by another process. A lock
+// that cannot be created (e.g. in a read-only directory) is not enforced.
+func (s *State) acquireLock() {
+	f, err := os.OpenFile(lockPath(s.path), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		log.Warningf("failed to open palette lock: %s", err)
+		return
+	}
+
+	ok, err := tryLock(f)
+	if err != nil {
+		log.Warningf("failed to lock palette: %s", err)
+		f.Close()
+		return
+	}
+	if ok && !isCurrent(f) {
+		// lock file removed by its previous holder before locked here
+		unlock(f)
+		f.Close()
+		s.acquireLock()
+		return
+	}
+	if !ok {
+		b, _ := ioutil.ReadAll(f)
+		f.Close()
+		s.readOnly = true
+		s.lockHolder = strings.TrimSpace(string(b))
+		log.Infof("palette locked by pid %s, opening read-only", s.lockHolder)
+		return
+	}
+
+	f.Truncate(0)
+	f.WriteAt([]byte(fmt.Sprintf("%d\n", os.Getpid())), 0)
+	s.lockFile = f
+	s.readOnly = false
+	s.lockHolder = ""
+}
+
+// isCurrent returns whether the open lock file f is still present at its
+// path, not having been removed on release by another process
+func isCurrent(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	pfi, err := os.Stat(f.Name())
+	return err == nil && os.SameFile(fi, pfi)
+}
+
+// ReadOnly returns whether the palette is locked by another process, in
+// which case it may not be saved
+func (s *State) ReadOnly() bool { return s.readOnly }
+
+// LockHolder returns the process ID of the process holding the palette
+// lock, if read-only
+func (s *State) LockHolder() string { return s.lockHolder }
+
+// TakeLock takes over the palette lock from another process, such as a
+// hung or stale tcolors instance, making state writable. The other process
+// may no longer save safely.
+func (s *State) TakeLock() error {
+	if !s.readOnly {
+		return nil
+	}
+	if err := os.Remove(lockPath(s.path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove palette lock: %s", err)
+	}
+	s.acquireLock()
+	if s.readOnly {
+		return fmt.Errorf("failed to take palette lock from pid %s", s.lockHolder)
+	}
+	return nil
+}
+
+// Close releases the palette lock held by state, if any, removing the lock
+// file
+func (s *State) Close() {
+	if s.lockFile == nil {
+		return
+	}
+	// removed while still held, such that no other process locks it
+	os.Remove(s.lockFile.Name())
+	unlock(s.lockFile)
+	s.lockFile.Close()
+	s.lockFile = nil
+}
+
+func (s *State) readOnlyErr() error {
+	return fmt.Errorf("palette is locked by another tcolors process (pid %s)", s.lockHolder)
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package state
+
+import "os"
+
+// tryLock always succeeds where flock is unavailable; palettes are not
+// protected from concurrent instances on these platforms
+func tryLock(f *os.File) (bool, error) { return true, nil }
+
+func unlock(f *os.File) error { return nil }
@@ -0,0 +1,119 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// savedPalette returns the path of a new saved palette in dir
+func savedPalette(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "palette.toml")
+	s := NewDefault()
+	s.path = path
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+	return path
+}
+
+func TestLockConflict(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := savedPalette(t, dir)
+
+	first, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if first.ReadOnly() {
+		t.Fatal("first Load read-only")
+	}
+
+	second, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	defer second.Close()
+	if !second.ReadOnly() {
+		t.Fatal("second Load of a locked palette not read-only")
+	}
+	if pid := strconv.Itoa(os.Getpid()); second.LockHolder() != pid {
+		t.Errorf("lock holder = %q, want %q", second.LockHolder(), pid)
+	}
+	if err := second.Save(); err == nil {
+		t.Error("read-only Save succeeded, want error")
+	}
+	if err := Restore(path, 1); err == nil || !strings.Contains(err.Error(), "open in another tab") {
+		t.Errorf("Restore of a locked palette: got error %v", err)
+	}
+
+	// released locks are removed, and free to be taken again
+	first.Close()
+	if _, err := os.Stat(lockPath(path)); !os.IsNotExist(err) {
+		t.Errorf("lock file remains after Close: %v", err)
+	}
+	third, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	defer third.Close()
+	if third.ReadOnly() {
+		t.Error("Load after Close read-only")
+	}
+}
+
+func TestTakeLock(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := savedPalette(t, dir)
+
+	first, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	defer first.Close()
+	second, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	defer second.Close()
+
+	if err := second.TakeLock(); err != nil {
+		t.Fatalf("TakeLock: %s", err)
+	}
+	if second.ReadOnly() {
+		t.Error("read-only after TakeLock")
+	}
+	if err := second.Save(); err != nil {
+		t.Errorf("Save after TakeLock: %s", err)
+	}
+}
+
+func TestReadUnlocked(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := savedPalette(t, dir)
+
+	if _, err := Read(path); err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+	if _, err := os.Stat(lockPath(path)); !os.IsNotExist(err) {
+		t.Errorf("Read created a lock file: %v", err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	defer s.Close()
+	// a locked palette may still be read, as for printing
+	if _, err := Read(path); err != nil {
+		t.Errorf("Read of a locked palette: %s", err)
+	}
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const metaTimeFormat = time.RFC3339
+
+// Metadata describes the attribution and provenance of a palette
+type Metadata struct {
+	Author      string
+	Description string
+	Tags        []string
+	License     string
+	Created     time.Time // time of first save
+	Modified    time.Time // time of most recent save
+}
+
+// MetaField is a single labeled metadata value
+type MetaField struct {
+	Label string
+	Value string
+}
+
+// Fields returns the labeled metadata values that are set, in display order
+func (m Metadata) Fields() []MetaField {
+	var fields []MetaField
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, MetaField{label, value})
+		}
+	}
+	add("Author", m.Author)
+	add("Description", m.Description)
+	add("Tags", strings.Join(m.Tags, ", "))
+	add("License", m.License)
+	if !m.Created.IsZero() {
+		add("Created", m.Created.Format(metaTimeFormat))
+	}
+	if !m.Modified.IsZero() {
+		add("Modified", m.Modified.Format(metaTimeFormat))
+	}
+	return fields
+}
+
+// Metadata returns the attribution and provenance of the palette
+func (s *State) Metadata() Metadata {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.meta
+}
+
+// readMetadata returns the Metadata given in config
+func (config *PaletteConfig) readMetadata() Metadata {
+	return Metadata{
+		Author:      config.Author,
+		Description: config.Description,
+		Tags:        config.Tags,
+		License:     config.License,
+		Created:     config.Created,
+		Modified:    config.Modified,
+	}
+}
+
+// setMetadata sets the metadata fields of config from m
+func (config *PaletteConfig) setMetadata(m Metadata) {
+	config.Author = m.Author
+	config.Description = m.Description
+	config.Tags = m.Tags
+	config.License = m.License
+	config.Created = m.Created
+	config.Modified = m.Modified
+}
+
+// setField sets the metadata value with the given label, as written by
+// Fields. Unknown labels are ignored.
+func (config *PaletteConfig) setField(label, value string) error {
+	switch label {
+	case "Author":
+		config.Author = value
+	case "Description":
+		config.Description = value
+	case "Tags":
+		config.Tags = nil
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				config.Tags = append(config.Tags, tag)
+			}
+		}
+	case "License":
+		config.License = value
+	case "Created", "Modified":
+		t, err := time.Parse(metaTimeFormat, value)
+		if err != nil {
+			return fmt.Errorf("malformed %s time %q", strings.ToLower(label), value)
+		}
+		if label == "Created" {
+			config.Created = t
+		} else {
+			config.Modified = t
+		}
+	}
+	return nil
+}
+
+// eachMetaLine calls fn with a "Label: value" line for each metadata value
+// set, for use in export header comments
+func (s *State) eachMetaLine(fn func(string)) {
+	for _, f := range s.meta.Fields() {
+		fn(f.Label + ": " + f.Value)
+	}
+}
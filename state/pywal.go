@@ -0,0 +1,142 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const walColorCount = 16
+
+// walConfig is the layout of pywal's colors.json
+type walConfig struct {
+	Wallpaper string `json:"wallpaper"`
+	Alpha     string `json:"alpha"`
+	Special   struct {
+		Background string `json:"background"`
+		Foreground string `json:"foreground"`
+		Cursor     string `json:"cursor"`
+	} `json:"special"`
+	Colors map[string]string `json:"colors"`
+}
+
+// pywalConfig holds the values of a pywal colors.json with no palette
+// equivalent, kept so that they survive loading and saving
+type pywalConfig struct {
+	Wallpaper  string `toml:"wallpaper,omitempty"`
+	Alpha      string `toml:"alpha,omitempty"`
+	Foreground string `toml:"foreground,omitempty"` // hex, without "#"
+	Cursor     string `toml:"cursor,omitempty"`     // hex, without "#"
+}
+
+func walKey(n int) string { return fmt.Sprintf("color%d", n) }
+
+// PywalString returns the current State in pywal colors.json format
+func (s *State) PywalString() (string, error) {
+	var buf bytes.Buffer
+	if err := encodePywal(&buf, s.config()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// encodePywal writes config in pywal colors.json format. Palette colors are
+// mapped in order to color0-color15, repeating where the palette has fewer
+// than 16. The wallpaper, alpha, foreground and cursor of a palette read
+// from colors.json are kept; otherwise foreground and cursor are set to the
+// color contrasting most with the background.
+func encodePywal(w io.Writer, config PaletteConfig) error {
+	if len(config.Colors) == 0 {
+		return fmt.Errorf("palette has no colors")
+	}
+
+	// load config into a temporary State to determine the foreground
+	tmp := NewDefault()
+	if err := tmp.apply(&config); err != nil {
+		return err
+	}
+	fg := walHex(tmp.foreground().PColor())
+
+	var wal pywalConfig
+	if config.Pywal != nil {
+		wal = *config.Pywal
+	}
+	if wal.Alpha == "" {
+		wal.Alpha = "100"
+	}
+	cursor := fg
+	if wal.Foreground != "" {
+		fg = "#" + wal.Foreground
+	}
+	if wal.Cursor != "" {
+		cursor = "#" + wal.Cursor
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	fmt.Fprintf(&buf, "  \"wallpaper\": %s,\n", walString(wal.Wallpaper))
+	fmt.Fprintf(&buf, "  \"alpha\": %s,\n", walString(wal.Alpha))
+	fmt.Fprintf(&buf, "  \"special\": {\n")
+	fmt.Fprintf(&buf, "    \"background\": \"%s\",\n", walHex(config.Background))
+	fmt.Fprintf(&buf, "    \"foreground\": %s,\n", walString(fg))
+	fmt.Fprintf(&buf, "    \"cursor\": %s\n", walString(cursor))
+	fmt.Fprintf(&buf, "  },\n")
+	fmt.Fprintf(&buf, "  \"colors\": {\n")
+	for n := 0; n < walColorCount; n++ {
+		sep := ","
+		if n == walColorCount-1 {
+			sep = ""
+		}
+		pc := config.Colors[n%len(config.Colors)]
+		fmt.Fprintf(&buf, "    \"%s\": \"%s\"%s\n", walKey(n), walHex(pc), sep)
+	}
+	fmt.Fprintf(&buf, "  }\n")
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func walHex(pc paletteColor) string {
+	return "#" + strings.ToLower(pc.HEX)
+}
+
+// walString returns s as a JSON string
+func walString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// decodePywal reads pywal colors.json into a PaletteConfig, using
+// special.background as the background and color0-color15 as palette
+// colors. The wallpaper, alpha, foreground and cursor are kept for saving.
+func decodePywal(b []byte) (*PaletteConfig, error) {
+	var wal walConfig
+	if err := json.Unmarshal(b, &wal); err != nil {
+		return nil, err
+	}
+
+	config := PaletteConfig{Version: paletteVersion}
+	config.Background = paletteColor{HEX: strings.TrimPrefix(wal.Special.Background, "#")}
+	config.Pywal = &pywalConfig{
+		Wallpaper:  wal.Wallpaper,
+		Alpha:      wal.Alpha,
+		Foreground: strings.TrimPrefix(wal.Special.Foreground, "#"),
+		Cursor:     strings.TrimPrefix(wal.Special.Cursor, "#"),
+	}
+
+	for n := 0; n < walColorCount; n++ {
+		hex, ok := wal.Colors[walKey(n)]
+		if !ok {
+			break
+		}
+		config.Colors = append(config.Colors, paletteColor{HEX: strings.TrimPrefix(hex, "#")})
+	}
+	if len(config.Colors) == 0 {
+		return nil, fmt.Errorf("missing colors.color0")
+	}
+
+	return &config, nil
+}
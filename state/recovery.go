@@ -0,0 +1,75 @@
+package state
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// RecoveryPath returns the path of the file unsaved changes to state are
+// periodically written to, for recovery after an unclean exit
+func (s *State) RecoveryPath() string { return recoveryPath(s.path) }
+
+// recoveryPath returns the path of the recovery file for the palette at path
+func recoveryPath(path string) string { return path + ".recovery" }
+
+// WriteRecovery writes the current State to its recovery file, in the
+// format of the palette file
+func (s *State) WriteRecovery() error {
+	s.lock.RLock()
+	config := s.config()
+	s.lock.RUnlock()
+
+	return writeAtomic(s.RecoveryPath(), false, func(w io.Writer) error {
+		return codecFor(s.path).encode(w, config)
+	})
+}
+
+// RemoveRecovery removes any recovery file for state
+func (s *State) RemoveRecovery() {
+	err := os.Remove(s.RecoveryPath())
+	if err != nil && !os.IsNotExist(err) {
+		log.Warningf("failed to remove recovery file: %s", err)
+	}
+}
+
+// Recovery returns the modification time of a recovery file newer than the
+// palette file, if one exists. Recovery files older than the palette file
+// are stale and removed.
+func (s *State) Recovery() (modTime time.Time, ok bool) {
+	rfi, err := os.Stat(s.RecoveryPath())
+	if err != nil {
+		return modTime, false
+	}
+	if fi, err := os.Stat(s.path); err == nil && !rfi.ModTime().After(fi.ModTime()) {
+		s.RemoveRecovery()
+		return modTime, false
+	}
+	return rfi.ModTime(), true
+}
+
+// Recover replaces the current State with the contents of its recovery
+// file. Recovered changes remain unsaved until the next save.
+func (s *State) Recover() error {
+	b, err := ioutil.ReadFile(s.RecoveryPath())
+	if err != nil {
+		return err
+	}
+	config, err := codecFor(s.path).decode(b)
+	if err != nil {
+		return err
+	}
+
+	other := NewDefault()
+	other.path = s.path
+	if err := other.apply(config); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.replace(other)
+	s.modified()
+	return nil
+}
@@ -0,0 +1,184 @@
+package state
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/teacat/noire"
+)
+
+// paletteVersion is the current palette file schema version, written on
+// save. TOML palette files without a version key are version 0; palettes in
+// other formats have no schema version and are read as the current one.
+const paletteVersion = 1
+
+// colorTolerance is the maximum per-channel RGB difference allowed between
+// the rgb, hsv and hex values of a color, accounting for rounding
+const colorTolerance = 2
+
+// migrations upgrade a PaletteConfig from the version at their index to the
+// following version
+var migrations = []func(*PaletteConfig){
+	migrateV0,
+}
+
+// migrate upgrades config to the current schema version
+func migrate(config *PaletteConfig) error {
+	if err := checkVersion(config.Version); err != nil {
+		return err
+	}
+	for config.Version < paletteVersion {
+		log.Infof("migrating palette from version %d", config.Version)
+		migrations[config.Version](config)
+		config.Version++
+	}
+	return nil
+}
+
+func checkVersion(version int) error {
+	if version > paletteVersion {
+		return fmt.Errorf("palette version %d is newer than supported (%d)", version, paletteVersion)
+	}
+	if version < 0 {
+		return fmt.Errorf("invalid palette version %d", version)
+	}
+	return nil
+}
+
+// migrateV0 resolves colors with disagreeing rgb, hsv and hex values, which
+// version 0 palettes read by precedence without error. The first of rgb,
+// hsv and hex given is kept.
+func migrateV0(config *PaletteConfig) {
+	config.Background.resolve("background")
+	for n := range config.Colors {
+		config.Colors[n].resolve(fmt.Sprintf("color%d", n))
+	}
+}
+
+// resolve discards all but the first of the rgb, hsv and hex values given,
+// in that order, if they disagree
+func (pc *paletteColor) resolve(table string) {
+	if pc.consistent() == nil {
+		return
+	}
+	switch {
+	case len(pc.RGB) != 0:
+		log.Warningf("[%s] rgb, hsv and hex disagree, using rgb", table)
+		pc.HSV, pc.HEX = nil, ""
+	case len(pc.HSV) != 0:
+		log.Warningf("[%s] hsv and hex disagree, using hsv", table)
+		pc.HEX = ""
+	}
+}
+
+// consistent returns an error if more than one of rgb, hsv and hex are
+// given and they do not describe the same color
+func (pc *paletteColor) consistent() error {
+	type def struct {
+		field string
+		nc    *noire.Color
+	}
+	var defs []def
+	if len(pc.RGB) != 0 && pc.validRGB() == nil {
+		defs = append(defs, def{"rgb", noire.NewRGB(float64(pc.RGB[0]), float64(pc.RGB[1]), float64(pc.RGB[2]))})
+	}
+	if len(pc.HSV) != 0 && pc.validHSV() == nil {
+		defs = append(defs, def{"hsv", noire.NewHSV(pc.HSV[0], pc.HSV[1], pc.HSV[2])})
+	}
+	if rgba, err := parseHex(pc.HEX); len(pc.HEX) != 0 && err == nil {
+		defs = append(defs, def{"hex", noire.NewRGB(float64(rgba[0]), float64(rgba[1]), float64(rgba[2]))})
+	}
+
+	// alpha given both explicitly and in RRGGBBAA hex
+	if rgba, err := parseHex(pc.HEX); pc.Alpha != nil && len(strings.TrimPrefix(pc.HEX, "#")) == 8 && err == nil {
+		if math.Abs(*pc.Alpha-float64(rgba[3])/255*opaque) > 1 {
+			return fmt.Errorf("alpha and hex values disagree")
+		}
+	}
+
+	if len(defs) < 2 {
+		return nil
+	}
+	for _, d := range defs[1:] {
+		if !sameColor(defs[0].nc, d.nc) {
+			return fmt.Errorf("%s and %s values disagree", defs[0].field, d.field)
+		}
+	}
+	return nil
+}
+
+func sameColor(a, b *noire.Color) bool {
+	ar, ag, ab := a.RGB()
+	br, bg, bb := b.RGB()
+	return math.Abs(ar-br) <= colorTolerance &&
+		math.Abs(ag-bg) <= colorTolerance &&
+		math.Abs(ab-bb) <= colorTolerance
+}
+
+// configProblem is a problem found in a PaletteConfig, located by the table
+// it occurs in
+type configProblem struct {
+	table string // e.g. "color2", "background", "vim"; empty for the top level
+	msg   string
+}
+
+func (p configProblem) Error() string {
+	if p.table == "" {
+		return p.msg
+	}
+	return fmt.Sprintf("[%s] %s", p.table, p.msg)
+}
+
+// problems returns all problems found in config, in file order
+func (config *PaletteConfig) problems() []configProblem {
+	var problems []configProblem
+	add := func(table string, err error) {
+		problems = append(problems, configProblem{table, err.Error()})
+	}
+
+	if err := checkVersion(config.Version); err != nil {
+		add("version", err)
+	}
+	if len(config.Colors) == 0 {
+		add("", fmt.Errorf("palette has no colors"))
+	}
+	if len(config.Colors) > maxSubStateCount {
+		add("", fmt.Errorf("maximum palette size (%d) exceeded", maxSubStateCount))
+	}
+
+	if err := config.Background.check(); err != nil && err.Error() != "missing definition" {
+		add("background", err)
+	}
+	for n := range config.Colors {
+		if err := config.Colors[n].check(); err != nil {
+			add(fmt.Sprintf("color%d", n), err)
+		}
+	}
+
+	if err := validVimGroups(config.Vim); err != nil {
+		add("vim", err)
+	}
+	return problems
+}
+
+// check returns an error if pc is not a valid, consistent color definition
+func (pc *paletteColor) check() error {
+	if _, err := pc.readColor(); err != nil {
+		return err
+	}
+	if len(pc.HSV) != 0 {
+		if err := pc.validHSV(); err != nil {
+			return err
+		}
+	}
+	if len(pc.HEX) != 0 {
+		if _, err := parseHex(pc.HEX); err != nil {
+			return err
+		}
+	}
+	if _, _, err := pc.readAlpha(); err != nil {
+		return err
+	}
+	return pc.consistent()
+}
@@ -0,0 +1,184 @@
+package state
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PaletteConfig
+		want    paletteColor
+		wantErr string
+	}{
+		{
+			name:   "v0 consistent",
+			config: PaletteConfig{Colors: []paletteColor{{RGB: []int{255, 0, 0}, HEX: "FF0000"}}},
+			want:   paletteColor{RGB: []int{255, 0, 0}, HEX: "FF0000"},
+		},
+		{
+			name:   "v0 rgb and hex disagree",
+			config: PaletteConfig{Colors: []paletteColor{{RGB: []int{255, 0, 0}, HEX: "0000FF"}}},
+			want:   paletteColor{RGB: []int{255, 0, 0}},
+		},
+		{
+			name:   "v0 hsv and hex disagree",
+			config: PaletteConfig{Colors: []paletteColor{{HSV: []float64{120, 100, 100}, HEX: "0000FF"}}},
+			want:   paletteColor{HSV: []float64{120, 100, 100}},
+		},
+		{
+			name:   "v1 untouched",
+			config: PaletteConfig{Version: 1, Colors: []paletteColor{{RGB: []int{255, 0, 0}, HEX: "0000FF"}}},
+			want:   paletteColor{RGB: []int{255, 0, 0}, HEX: "0000FF"},
+		},
+		{name: "newer version", config: PaletteConfig{Version: paletteVersion + 1}, wantErr: "newer than supported"},
+		{name: "negative version", config: PaletteConfig{Version: -1}, wantErr: "invalid palette version"},
+	}
+
+	for _, tt := range tests {
+		err := migrate(&tt.config)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: migrate() returned error: %s", tt.name, err)
+			continue
+		}
+		if tt.config.Version != paletteVersion {
+			t.Errorf("%s: version = %d, want %d", tt.name, tt.config.Version, paletteVersion)
+		}
+		got := tt.config.Colors[0]
+		if len(got.RGB) != len(tt.want.RGB) || len(got.HSV) != len(tt.want.HSV) || got.HEX != tt.want.HEX {
+			t.Errorf("%s: color = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+const paletteV0 = `name = "old"
+
+[background]
+rgb = [0, 0, 0]
+
+[[color]]
+rgb = [255, 0, 0]
+hex = "0000FF"
+
+[[color]]
+hex = "00FF00"
+`
+
+func TestMigrateV0File(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "old.toml")
+	if err := ioutil.WriteFile(path, []byte(paletteV0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+	s.Close()
+
+	problems, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Validate: %s", err)
+	}
+	for _, p := range problems {
+		t.Errorf("saved palette line %d: %s", p.Line, p.Msg)
+	}
+
+	b, _ := ioutil.ReadFile(path)
+	if !strings.Contains(string(b), "version = 1") {
+		t.Errorf("saved palette not version 1:\n%s", b)
+	}
+	got, err := read(path)
+	if err != nil {
+		t.Fatalf("reload: %s", err)
+	}
+	if hex := got.HexString(); hex != "000000, FF0000, 00FF00" {
+		t.Errorf("reloaded as %s", hex)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		want     []Problem
+	}{
+		{
+			name:     "valid",
+			file:     "ok.toml",
+			contents: "version = 1\n[background]\nhex = \"000000\"\n[[color]]\nhex = \"FF0000\"\n",
+		},
+		{
+			name:     "v1 disagreement",
+			file:     "bad.toml",
+			contents: "version = 1\n[[color]]\nhex = \"FF0000\"\n[[color]]\nrgb = [255, 0, 0]\nhex = \"0000FF\"\n",
+			want:     []Problem{{Line: 4, Msg: "[color1] rgb and hex values disagree"}},
+		},
+		{
+			name:     "several problems",
+			file:     "bad.toml",
+			contents: "version = 2\n[[color]]\nrgb = [256, 0, 0]\n[vim]\nComment = -1\n",
+			want: []Problem{
+				{Line: 1, Msg: "[version] palette version 2 is newer"},
+				{Line: 2, Msg: "[color0]"},
+				{Line: 4, Msg: "[vim] highlight group Comment"},
+			},
+		},
+		{
+			name:     "no colors",
+			file:     "empty.toml",
+			contents: "version = 1\n",
+			want:     []Problem{{Msg: "palette has no colors"}},
+		},
+		{
+			name:     "decode error",
+			file:     "bad.gpl",
+			contents: "GIMP Palette\n255 0\n",
+			want:     []Problem{{Line: 2, Msg: "too few values"}},
+		},
+		{
+			name:     "other format",
+			file:     "ok.gpl",
+			contents: "GIMP Palette\n255 0 0\n",
+		},
+	}
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := ioutil.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Validate(path)
+		if err != nil {
+			t.Errorf("%s: Validate() returned error: %s", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got problems %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for n, p := range got {
+			if p.Line != tt.want[n].Line || !strings.Contains(p.Msg, tt.want[n].Msg) {
+				t.Errorf("%s: problem %d = %+v, want %+v", tt.name, n, p, tt.want[n])
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKeys are the color properties a palette may be sorted by
+var SortKeys = []string{"hue", "saturation", "value", "luminance"}
+
+// Sort orders palette colors in ascending order of the given property,
+// keeping the selected color selected
+func (s *State) Sort(key string) error {
+	var fn func(*subState) float64
+	switch strings.ToLower(key) {
+	case "hue", "h":
+		fn = func(ss *subState) float64 { return ss.hue }
+	case "saturation", "sat", "s":
+		fn = func(ss *subState) float64 { _, sat, _ := ss.HSV(); return sat }
+	case "value", "val", "v":
+		fn = func(ss *subState) float64 { _, _, v := ss.HSV(); return v }
+	case "luminance", "lum", "l":
+		fn = func(ss *subState) float64 { return ss.Luminance() }
+	default:
+		return fmt.Errorf("unknown sort key: %s", key)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	selected := s.sstates[s.pos]
+	sort.SliceStable(s.sstates, func(i, j int) bool {
+		return fn(s.sstates[i]) < fn(s.sstates[j])
+	})
+	for n, ss := range s.sstates {
+		if ss == selected {
+			s.pos = n
+		}
+	}
+	s.modified()
+	s.pending = AllChanged
+	return nil
+}
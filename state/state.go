@@ -2,7 +2,12 @@ package state
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -13,27 +18,57 @@ import (
 	"github.com/teacat/noire"
 )
 
+// MaxColors is the largest number of colors a palette may hold
+const MaxColors = 16
+
 var (
 	defaultSubStateCount = 7
-	maxSubStateCount     = 14
+	maxSubStateCount     = MaxColors
 	log                  = logging.Init()
 	malformedErr         = fmt.Errorf("malformed state file")
 )
 
 type State struct {
 	name       string
+	meta       Metadata
 	path       string
 	pos        int
 	isNew      bool
 	background *subState
-	sstates    []*subState // must be odd number for centering to work properly
+	sstates    []*subState // palette colors, at most maxSubStateCount
 	lock       sync.RWMutex
 	pending    Change
+	dirty      bool              // modified since last load or save
+	rev        uint64            // incremented on each modification
+	diskSum    [sha256.Size]byte // checksum of palette file as last loaded or saved
+	vimGroups  map[string]int    // highlight group to color index
+	pywal      *pywalConfig      // pywal values kept from colors.json
+	lockFile   *os.File          // held palette lock, if any
+	readOnly   bool              // palette locked by another process
+	lockHolder string            // pid of process holding the palette lock
 }
 
-// Load attempts to read a stored state from disk. If no stored state exists or
-// is readable, a default State and error will be returned.
+// Load opens a stored state from disk for editing. If no stored state exists
+// or is readable, a default State and error will be returned. An advisory
+// lock on the palette is first taken, with the returned State read-only if
+// the lock is held by another process. The lock is released by Close.
 func Load(path string) (*State, error) {
+	s := NewDefault()
+	s.path = path
+	s.acquireLock()
+	if err := s.load(); err != nil {
+		s.Close()
+		return s, fmt.Errorf("failed to load state: %s", err)
+	}
+	return s, nil
+}
+
+// Read returns the stored state at path without locking it, for
+// non-interactive use such as printing the palette
+func Read(path string) (*State, error) { return read(path) }
+
+// read returns the stored state at path, without locking
+func read(path string) (*State, error) {
 	s := NewDefault()
 	s.path = path
 	if err := s.load(); err != nil {
@@ -45,12 +80,12 @@ func Load(path string) (*State, error) {
 // NewDefault returns a State initialized with default colors
 func NewDefault() *State {
 	s := New()
-	s.background = &subState{noire.NewHSV(0, 0, 0), 0}
+	s.background = newSubState(noire.NewHSV(0, 0, 0))
 	s.sstates = make([]*subState, defaultSubStateCount)
 
 	hue := 20.0
 	for n := range s.sstates {
-		s.sstates[n] = &subState{noire.NewHSV(hue, 80, 100), hue}
+		s.sstates[n] = &subState{Color: noire.NewHSV(hue, 80, 100), hue: hue, alpha: opaque}
 		hue += 30
 	}
 
@@ -59,6 +94,26 @@ func NewDefault() *State {
 
 func New() *State { return &State{pending: AllChanged} }
 
+// FromColors returns a State with the given name, background and colors, to
+// be saved at path
+func FromColors(path, name string, bg color.Color, colors []color.Color) (*State, error) {
+	config := &PaletteConfig{
+		Version:    paletteVersion,
+		Name:       name,
+		Background: rgbPaletteColor(bg),
+	}
+	for _, c := range colors {
+		config.Colors = append(config.Colors, rgbPaletteColor(c))
+	}
+
+	s := New()
+	s.path = path
+	if err := s.apply(config); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 // IsNew returns whether this state is newly created.
 // returns false if state was successfully loaded from file.
 func (s *State) IsNew() bool { return s.isNew }
@@ -66,22 +121,156 @@ func (s *State) IsNew() bool { return s.isNew }
 // Path returns the persistent filepath for state
 func (s *State) Path() string { return s.path }
 
+// SamePath returns whether paths a and b refer to the same palette file,
+// resolving relative paths and symlinks
+func SamePath(a, b string) bool { return absPath(a) == absPath(b) }
+
+func absPath(path string) string {
+	path = resolveLink(path)
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// Dirty returns whether state has been modified since it was last loaded
+// or saved
+func (s *State) Dirty() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.dirty
+}
+
+// Revision returns a counter incremented on each modification of state
+func (s *State) Revision() uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.rev
+}
+
+// modified marks state as changed. The caller must hold s.lock.
+func (s *State) modified() {
+	s.dirty = true
+	s.rev++
+}
+
 func (s *State) Save() error {
+	if s.readOnly {
+		return fmt.Errorf("failed to save palette state: %s", s.readOnlyErr())
+	}
 	if err := s.save(); err != nil {
 		return fmt.Errorf("failed to save palette state: %s", err)
 	}
+	s.lock.Lock()
+	s.dirty = false
+	s.lock.Unlock()
+	s.RemoveRecovery()
+	return nil
+}
+
+// SaveTo writes the current State to path, in the format given by its
+// extension. The persistent filepath for state is unchanged, and a path
+// naming it is saved as by Save. Palettes locked by another process, or by
+// another State, are not overwritten.
+func (s *State) SaveTo(path string) error {
+	if SamePath(path, s.path) {
+		return s.Save()
+	}
+	if err := checkUnlocked(path); err != nil {
+		return fmt.Errorf("failed to save palette: %s", err)
+	}
+
+	config := s.stampedConfig()
+	err := writeAtomic(path, false, func(w io.Writer) error {
+		return codecFor(path).encode(w, config)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save palette: %s", err)
+	}
+	return nil
+}
+
+// Open replaces the current State with the palette stored at path, which
+// becomes the new persistent filepath for state
+func (s *State) Open(path string) error {
+	if SamePath(path, s.path) {
+		return s.Reload()
+	}
+	other, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Close()
+	s.replace(other)
+	s.isNew = other.isNew
+	s.diskSum = other.diskSum
+	s.lockFile = other.lockFile
+	s.readOnly = other.readOnly
+	s.lockHolder = other.lockHolder
+	s.dirty = false
 	return nil
 }
 
+// replace sets the palette of the current State to that of other, selecting
+// the first color. The caller must hold s.lock.
+func (s *State) replace(other *State) {
+	s.name = other.name
+	s.meta = other.meta
+	s.path = other.path
+	s.background = other.background
+	s.sstates = other.sstates
+	s.vimGroups = other.vimGroups
+	s.pywal = other.pywal
+	s.pos = 0
+	s.pending = AllChanged
+}
+
 func (s *State) Name() string {
 	if s.name == "" {
-		return strings.ReplaceAll(filepath.Base(s.path), ".toml", "")
+		base := filepath.Base(s.path)
+		return strings.TrimSuffix(base, filepath.Ext(base))
 	}
 	return s.name
 }
 
 func (s *State) Background() tcell.Color { return s.background.TColor() }
 
+// foreground returns the palette color with the highest contrast ratio
+// against the background
+func (s *State) foreground() *subState {
+	var best *subState
+	var bestRatio float64
+	for _, ss := range s.sstates {
+		if ratio := ss.ContrastRatio(s.background); best == nil || ratio > bestRatio {
+			best, bestRatio = ss, ratio
+		}
+	}
+	return best
+}
+
+// Swatches returns the palette colors composited over the background,
+// as displayed
+func (s *State) Swatches() []tcell.Color {
+	a := make([]tcell.Color, len(s.sstates))
+	for n := range s.sstates {
+		a[n] = s.sstates[n].Over(s.background)
+	}
+	return a
+}
+
+// HasAlpha returns whether any palette color is translucent
+func (s *State) HasAlpha() bool {
+	for _, ss := range s.sstates {
+		if !ss.Opaque() {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *State) SubColors() []tcell.Color {
 	a := make([]tcell.Color, len(s.sstates))
 	for n := range s.sstates {
@@ -94,9 +283,31 @@ func (s *State) Pos() int            { return s.pos }
 func (s *State) Len() int            { return len(s.sstates) }
 func (s *State) Selected() *subState { return s.sstates[s.Pos()] }
 
+// FixedSize returns an error if the palette file format requires a fixed
+// number of colors, such that colors may not be added or removed
+func (s *State) FixedSize() error {
+	if size := codecFor(s.path).size; size != 0 {
+		return fmt.Errorf("%s palettes must have exactly %d colors", filepath.Ext(s.path), size)
+	}
+	return nil
+}
+
+// fitSize pads or truncates the palette colors to the number required by
+// the palette file format, if any
+func (s *State) fitSize() {
+	size := codecFor(s.path).size
+	if size == 0 {
+		return
+	}
+	for len(s.sstates) < size {
+		s.sstates = append(s.sstates, newDefaultSubState())
+	}
+	s.sstates = s.sstates[:size]
+}
+
 // Add adds a new subState after the current position
 func (s *State) Add() (ok bool) {
-	if s.Len() >= maxSubStateCount {
+	if s.Len() >= maxSubStateCount || s.FixedSize() != nil {
 		return
 	}
 
@@ -112,12 +323,13 @@ func (s *State) Add() (ok bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.sstates = newSStates
+	s.modified()
 	return true
 }
 
 // Remove removes the subState at the current position
 func (s *State) Remove() (ok bool) {
-	if s.Len() <= 1 {
+	if s.Len() <= 1 || s.FixedSize() != nil {
 		return
 	}
 
@@ -132,6 +344,7 @@ func (s *State) Remove() (ok bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.sstates = newSStates
+	s.modified()
 	if s.pos >= s.Len() {
 		s.pos = s.Len() - 1
 	}
@@ -179,6 +392,17 @@ func (s *State) Prev() {
 	s.pending = AllChanged
 }
 
+// SetPos sets the current substate position
+func (s *State) SetPos(n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if n < 0 || n >= s.Len() || n == s.pos {
+		return
+	}
+	s.pos = n
+	s.pending = AllChanged
+}
+
 // Return state Change since previous flush
 func (s *State) Flush() Change {
 	s.lock.Lock()
@@ -192,6 +416,7 @@ func (s *State) SetHue(n float64) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.Selected().SetHue(n)
+	s.modified()
 	s.pending = s.pending | HueChanged
 }
 
@@ -199,6 +424,7 @@ func (s *State) SetSaturation(n float64) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.Selected().SetSaturation(n)
+	s.modified()
 	s.pending = s.pending | SaturationChanged
 }
 
@@ -206,12 +432,71 @@ func (s *State) SetValue(n float64) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.Selected().SetValue(n)
+	s.modified()
 	s.pending = s.pending | ValueChanged
 }
 
-// TableString returns an ascii table formatted representation of the current State
+func (s *State) Alpha() float64 { return s.Selected().Alpha() }
+
+// SetAlpha sets the opacity of the selected color, from 0 to 100
+func (s *State) SetAlpha(n float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Selected().alpha = math.Max(0, math.Min(n, opaque))
+	s.modified()
+	s.pending = s.pending | AlphaChanged
+}
+
+// SetHex replaces the selected color with the given hex color
+func (s *State) SetHex(hex string) error {
+	return s.setColor(paletteColor{HEX: hex})
+}
+
+// SetRGB replaces the selected color with the given RGB color
+func (s *State) SetRGB(r, g, b int) error {
+	return s.setColor(paletteColor{RGB: []int{r, g, b}})
+}
+
+// SetHSV replaces the selected color with the given HSV color
+func (s *State) SetHSV(h, sat, v float64) error {
+	return s.setColor(paletteColor{HSV: []float64{h, sat, v}})
+}
+
+func (s *State) setColor(pc paletteColor) error {
+	nc, err := pc.readColor()
+	if err != nil {
+		return err
+	}
+
+	alpha, ok, err := pc.readAlpha()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	prev := s.Selected()
+	s.sstates[s.pos] = newSubState(nc)
+	s.sstates[s.pos].name = prev.name
+	s.sstates[s.pos].alpha = prev.alpha
+	if ok {
+		s.sstates[s.pos].alpha = alpha
+	}
+	s.modified()
+	s.pending = s.pending | HueChanged | SaturationChanged | ValueChanged | AlphaChanged
+	return nil
+}
+
+// TableString returns an ascii table formatted representation of the current
+// State, headed by the palette name and metadata
 func (s *State) TableString() string {
 	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Name: %s\n", s.Name())
+	s.eachMetaLine(func(line string) {
+		fmt.Fprintf(&buf, "%s\n", line)
+	})
+	buf.WriteString("\n")
+
 	table := tablewriter.NewWriter(&buf)
 	table.SetHeader([]string{"#", "Hex", "HSV", "RGB", "TERM"})
 
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempDir returns a new temporary directory and a function removing it
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "tcolors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestSaveReloadHex(t *testing.T) {
+	tests := []string{
+		"FF0001", // hue just below 360
+		"FF0000",
+		"000000",
+		"FFFFFF",
+		"7F7F80",
+		"010000",
+		"FE00FF",
+	}
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	for n, hex := range tests {
+		path := filepath.Join(dir, fmt.Sprintf("palette%d.toml", n))
+		s, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load: %s", err)
+		}
+		if err := s.SetHex(hex); err != nil {
+			t.Fatalf("SetHex(%q): %s", hex, err)
+		}
+		if err := s.Save(); err != nil {
+			t.Fatalf("%s: Save: %s", hex, err)
+		}
+		s.Close()
+
+		got, err := read(path)
+		if err != nil {
+			t.Errorf("%s: reload: %s", hex, err)
+			continue
+		}
+		if h := got.sstates[0].Hex(); h != hex {
+			t.Errorf("%s: reloaded as %s", hex, h)
+		}
+		if h := got.sstates[0].PColor().HSV[0]; h < 0 || h >= 360 {
+			t.Errorf("%s: saved hue %v out of range", hex, h)
+		}
+	}
+}
+
+func TestSaveToSamePath(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "palette.toml")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	defer s.Close()
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+
+	s.SetHex("102030")
+	if err := s.SaveTo(filepath.Join(dir, ".", "palette.toml")); err != nil {
+		t.Fatalf("SaveTo: %s", err)
+	}
+	if s.Dirty() {
+		t.Error("palette dirty after SaveTo its own path")
+	}
+	if s.DiskChanged() {
+		t.Error("own write seen as a change on disk")
+	}
+	if backups, _ := Backups(path); len(backups) != 1 {
+		t.Errorf("got %d backups, want 1", len(backups))
+	}
+
+	// a copy leaves the palette itself unsaved
+	s.SetHex("405060")
+	if err := s.SaveTo(filepath.Join(dir, "copy.gpl")); err != nil {
+		t.Fatalf("SaveTo: %s", err)
+	}
+	if !s.Dirty() {
+		t.Error("palette clean after SaveTo another path")
+	}
+}
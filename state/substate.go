@@ -2,18 +2,28 @@ package state
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/gdamore/tcell"
 	"github.com/teacat/noire"
 )
 
+// opaque is the alpha value of a fully opaque color
+const opaque = 100.0
+
 type subState struct {
 	*noire.Color
-	hue float64
+	hue   float64
+	alpha float64 // opacity, from 0 to 100
+	name  string
+}
+
+func newSubState(nc *noire.Color) *subState {
+	return &subState{Color: nc, hue: nc.Hue(), alpha: opaque}
 }
 
 func newDefaultSubState() *subState {
-	return &subState{noire.NewRGB(128, 128, 128), 128}
+	return &subState{Color: noire.NewRGB(128, 128, 128), hue: 128, alpha: opaque}
 }
 
 func (ss *subState) NColor() *noire.Color {
@@ -30,12 +40,43 @@ func (ss *subState) TColor() tcell.Color {
 func (ss *subState) PColor() (pc paletteColor) {
 	r, g, b := ss.RGB()
 	h, s, v := ss.HSV()
+	// keep hue within [0, 360), as required on load
+	if h = math.Mod(h, 360); h < 0 {
+		h += 360
+	}
+	pc.Name = ss.name
 	pc.RGB = []int{int(r), int(g), int(b)}
-	pc.HEX = ss.HexString()
+	pc.HEX = ss.Hex()
 	pc.HSV = []float64{h, s, v}
+	if !ss.Opaque() {
+		alpha := ss.alpha
+		pc.Alpha = &alpha
+	}
 	return pc
 }
 
+// Alpha returns the opacity of the current subState, from 0 to 100
+func (ss *subState) Alpha() float64 { return ss.alpha }
+
+// Opaque returns whether the current subState is fully opaque
+func (ss *subState) Opaque() bool { return ss.alpha >= opaque }
+
+// alpha255 returns the opacity of the current subState from 0 to 255
+func (ss *subState) alpha255() int { return int(math.Round(ss.alpha / opaque * 255)) }
+
+// Over returns the current subState composited over bg, according to its
+// opacity
+func (ss *subState) Over(bg *subState) tcell.Color {
+	if ss.Opaque() {
+		return ss.TColor()
+	}
+	r, g, b := ss.RGB()
+	br, bgg, bb := bg.RGB()
+	a := ss.alpha / opaque
+	mix := func(fg, bg float64) int32 { return int32(math.Round(fg*a + bg*(1-a))) }
+	return tcell.NewRGBColor(mix(r, br), mix(g, bgg), mix(b, bb))
+}
+
 func (ss *subState) HSVIdx(n uint8) float64 {
 	h, s, v := ss.HSV()
 	switch n {
@@ -50,6 +91,9 @@ func (ss *subState) HSVIdx(n uint8) float64 {
 	}
 }
 
+// Name returns the optional name given to the current subState
+func (ss *subState) Name() string { return ss.name }
+
 func (ss *subState) Hue() float64 {
 	return ss.hue
 }
@@ -78,8 +122,13 @@ func (ss *subState) SetValue(n float64) {
 	ss.Color = noire.NewHSV(ss.hue, s, n)
 }
 
+// HexString returns the current subState in RRGGBB form, or RRGGBBAA
+// form if translucent
 func (ss *subState) HexString() string {
-	return ss.Hex()
+	if ss.Opaque() {
+		return ss.Hex()
+	}
+	return fmt.Sprintf("%s%02X", ss.Hex(), ss.alpha255())
 }
 
 func (ss *subState) HSVString() string {
@@ -87,9 +136,14 @@ func (ss *subState) HSVString() string {
 	return fmt.Sprintf("%03.0f %03.0f %03.0f", h, s, v)
 }
 
+// RGBString returns the RGB components of the current subState, followed
+// by alpha (0-255) if translucent
 func (ss *subState) RGBString() string {
 	r, g, b := ss.RGB()
-	return fmt.Sprintf("%03.0f %03.0f %03.0f", r, g, b)
+	if ss.Opaque() {
+		return fmt.Sprintf("%03.0f %03.0f %03.0f", r, g, b)
+	}
+	return fmt.Sprintf("%03.0f %03.0f %03.0f %03d", r, g, b, ss.alpha255())
 }
 
 func (ss *subState) TermString() string {
@@ -97,3 +151,29 @@ func (ss *subState) TermString() string {
 	rgbx := fmt.Sprintf("%03.0f;%03.0f;%03.0f", r, g, b)
 	return fmt.Sprintf("\\033[38;2;%sm$@\\033[0;00m", rgbx)
 }
+
+// Luminance returns the relative luminance of the current subState, as
+// defined by WCAG 2.0
+func (ss *subState) Luminance() float64 {
+	r, g, b := ss.RGB()
+	return 0.2126*linearize(r) + 0.7152*linearize(g) + 0.0722*linearize(b)
+}
+
+// ContrastRatio returns the WCAG 2.0 contrast ratio between the current
+// and other subState
+func (ss *subState) ContrastRatio(other *subState) float64 {
+	l1, l2 := ss.Luminance(), other.Luminance()
+	if l2 > l1 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// linearize converts an 8-bit sRGB component to linear light
+func linearize(n float64) float64 {
+	n = n / 255
+	if n <= 0.03928 {
+		return n / 12.92
+	}
+	return math.Pow((n+0.055)/1.055, 2.4)
+}
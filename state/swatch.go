@@ -0,0 +1,62 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+
+	"github.com/bcicen/tcolors/swatch"
+)
+
+// minimum WCAG contrast ratio for normal text
+const minContrastAA = 4.5
+
+// PNGBytes returns the current State rendered as a PNG swatch sheet
+func (s *State) PNGBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := swatch.EncodePNG(&buf, s.sheet()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVGString returns the current State rendered as an SVG swatch sheet
+func (s *State) SVGString() string {
+	var buf bytes.Buffer
+	swatch.EncodeSVG(&buf, s.sheet())
+	return buf.String()
+}
+
+// sheet returns a swatch.Sheet for the current State, with each color
+// labelled with its hex and RGB values and contrast ratio against the
+// background
+func (s *State) sheet() swatch.Sheet {
+	sh := swatch.Sheet{
+		Title:      s.Name(),
+		Background: rgba(s.background),
+		Foreground: color.RGBA{255, 255, 255, 255},
+	}
+	// WCAG luminance threshold at which black text contrasts more than white
+	if s.background.Luminance() > 0.179 {
+		sh.Foreground = color.RGBA{0, 0, 0, 255}
+	}
+
+	for _, ss := range s.sstates {
+		ratio := ss.ContrastRatio(s.background)
+		contrast := fmt.Sprintf("%.2f:1", ratio)
+		if ratio < minContrastAA {
+			contrast += " LOW"
+		}
+		sh.Chips = append(sh.Chips, swatch.Chip{
+			Color:  rgba(ss),
+			Labels: []string{"#" + ss.HexString(), ss.RGBString(), contrast},
+		})
+	}
+
+	return sh
+}
+
+func rgba(ss *subState) color.RGBA {
+	r, g, b := ss.RGB()
+	return color.RGBA{uint8(r), uint8(g), uint8(b), 255}
+}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// designToken is a single W3C design token of type color
+type designToken struct {
+	Type  string `json:"$type"`
+	Value string `json:"$value"`
+}
+
+// tokenGroup is an ordered set of named design tokens
+type tokenGroup struct {
+	description string
+	names       []string
+	tokens      []designToken
+}
+
+func (tg *tokenGroup) add(name string, tok designToken) {
+	tg.names = append(tg.names, name)
+	tg.tokens = append(tg.tokens, tok)
+}
+
+// MarshalJSON implements json.Marshaler, preserving token order
+func (tg tokenGroup) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if tg.description != "" {
+		d, _ := json.Marshal(tg.description)
+		buf.WriteString(`"$description":`)
+		buf.Write(d)
+		if len(tg.names) > 0 {
+			buf.WriteByte(',')
+		}
+	}
+	for n, name := range tg.names {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(name)
+		v, err := json.Marshal(tg.tokens[n])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// TokensString returns the current State as W3C design tokens JSON, grouped
+// under the palette name and described by the palette description
+func (s *State) TokensString() string {
+	group := tokenGroup{description: s.meta.Description}
+	s.eachNamedColor(func(name string, ss *subState) {
+		group.add(name, designToken{"color", cssValue(ss, CSSHex)})
+	})
+
+	doc := map[string]tokenGroup{s.namespace(): group}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		// encoding of plain strings cannot fail
+		panic(err)
+	}
+	return string(b) + "\n"
+}
+
+// TailwindString returns the current State as a Tailwind CSS config module,
+// extending the theme colors with a group under the palette name
+func (s *State) TailwindString() string {
+	var buf bytes.Buffer
+	buf.WriteString("module.exports = {\n")
+	buf.WriteString("  theme: {\n")
+	buf.WriteString("    extend: {\n")
+	buf.WriteString("      colors: {\n")
+	fmt.Fprintf(&buf, "        '%s': {\n", s.namespace())
+	s.eachNamedColor(func(name string, ss *subState) {
+		fmt.Fprintf(&buf, "          '%s': '%s',\n", name, cssValue(ss, CSSHex))
+	})
+	buf.WriteString("        },\n")
+	buf.WriteString("      },\n")
+	buf.WriteString("    },\n")
+	buf.WriteString("  },\n")
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// namespace returns the palette name for use as an export namespace
+func (s *State) namespace() string {
+	if ns := slugify(s.Name()); ns != "" {
+		return ns
+	}
+	return "tcolors"
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTokensString(t *testing.T) {
+	tests := []struct {
+		name      string
+		palette   string
+		desc      string
+		wantGroup string
+	}{
+		{name: "named", palette: "My Palette", desc: "warm \"tones\"", wantGroup: "my-palette"},
+		{name: "unnamed", palette: "!!!", wantGroup: "tcolors"},
+	}
+
+	for _, tt := range tests {
+		s := testState(t, []int{0, 0, 0}, []int{255, 0, 0}, []int{0, 0, 255})
+		s.name = tt.palette
+		s.meta.Description = tt.desc
+		s.sstates[1].alpha = 50
+		out := s.TokensString()
+
+		var doc map[string]map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Errorf("%s: invalid JSON: %s\n%s", tt.name, err, out)
+			continue
+		}
+		group, ok := doc[tt.wantGroup]
+		if !ok || len(doc) != 1 {
+			t.Errorf("%s: got groups %v, want only %q", tt.name, doc, tt.wantGroup)
+			continue
+		}
+
+		var desc string
+		if raw, ok := group["$description"]; ok {
+			json.Unmarshal(raw, &desc)
+		}
+		if desc != tt.desc {
+			t.Errorf("%s: $description = %q, want %q", tt.name, desc, tt.desc)
+		}
+
+		want := map[string]string{"bg": "#000000", "color-0": "#ff0000", "color-1": "#0000ff80"}
+		for name, value := range want {
+			var tok designToken
+			if err := json.Unmarshal(group[name], &tok); err != nil {
+				t.Errorf("%s: token %s: %s", tt.name, name, err)
+				continue
+			}
+			if tok.Type != "color" || tok.Value != value {
+				t.Errorf("%s: token %s = %+v, want color %s", tt.name, name, tok, value)
+			}
+		}
+
+		// tokens keep palette order
+		if i, j := strings.Index(out, `"bg"`), strings.Index(out, `"color-0"`); i > j {
+			t.Errorf("%s: bg written after color-0", tt.name)
+		}
+		if i, j := strings.Index(out, `"color-0"`), strings.Index(out, `"color-1"`); i > j {
+			t.Errorf("%s: color-0 written after color-1", tt.name)
+		}
+	}
+}
+
+func TestTailwindString(t *testing.T) {
+	s := testState(t, []int{0, 0, 0}, []int{255, 0, 0})
+	s.name = "My Palette"
+	out := s.TailwindString()
+
+	for _, want := range []string{
+		"module.exports = {\n",
+		"        'my-palette': {\n",
+		"          'bg': '#000000',\n",
+		"          'color-0': '#ff0000',\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	if open, close := strings.Count(out, "{"), strings.Count(out, "}"); open != close {
+		t.Errorf("unbalanced braces (%d open, %d close) in\n%s", open, close, out)
+	}
+}
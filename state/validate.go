@@ -0,0 +1,79 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Problem is a problem found in a palette file
+type Problem struct {
+	Line int // line number, or 0 if unknown
+	Msg  string
+}
+
+// errLineRe matches line numbers given in decoding errors
+var errLineRe = regexp.MustCompile(`[Ll]ine (\d+)`)
+
+// Validate checks the palette file at path, returning all problems found
+func Validate(path string) ([]Problem, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := codecFor(path).decode(b)
+	if err != nil {
+		p := Problem{Msg: err.Error()}
+		if m := errLineRe.FindStringSubmatch(p.Msg); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+		}
+		return []Problem{p}, nil
+	}
+
+	// other formats are read as TOML, as in codecFor
+	var lines map[string]int
+	ext := strings.ToLower(filepath.Ext(path))
+	if _, ok := codecs[ext]; !ok || ext == ".toml" {
+		lines = tomlTableLines(b)
+	}
+
+	var problems []Problem
+	for _, cp := range config.problems() {
+		problems = append(problems, Problem{Line: lines[cp.table], Msg: cp.Error()})
+	}
+	return problems, nil
+}
+
+// tomlTableLines returns the line number of each palette table in a TOML
+// palette file, keyed as in configProblem. The top level version key is
+// included as "version".
+func tomlTableLines(b []byte) map[string]int {
+	lines := make(map[string]int)
+	colorN := 0
+	table := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for lineN := 1; scanner.Scan(); lineN++ {
+		line := strings.Replace(strings.TrimSpace(scanner.Text()), " ", "", -1)
+		switch {
+		case line == "[[color]]":
+			table = fmt.Sprintf("color%d", colorN)
+			lines[table] = lineN
+			colorN++
+		case line == "[background]" || line == "[vim]":
+			table = strings.Trim(line, "[]")
+			lines[table] = lineN
+		case strings.HasPrefix(line, "["):
+			table = line
+		case table == "" && strings.HasPrefix(line, "version="):
+			lines["version"] = lineN
+		}
+	}
+	return lines
+}
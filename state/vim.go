@@ -0,0 +1,197 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const vimTerminalColors = 16
+
+// default palette index for each standard syntax group, used where not
+// overridden by the [vim] table of the palette file. Linked groups (e.g.
+// String -> Constant) fall back to their parent group by vim default.
+var defaultVimGroups = map[string]int{
+	"Comment":    6,
+	"Constant":   0,
+	"String":     3,
+	"Identifier": 5,
+	"Function":   6,
+	"Statement":  1,
+	"PreProc":    2,
+	"Type":       4,
+	"Special":    0,
+	"Underlined": 5,
+	"Error":      0,
+	"Todo":       1,
+}
+
+// VimString returns the current State as a vim colorscheme
+func (s *State) VimString() string {
+	var buf bytes.Buffer
+	name := s.namespace()
+	fg := s.foreground()
+
+	fmt.Fprintf(&buf, "\" Name: %s\n", name)
+	s.eachMetaLine(func(line string) {
+		fmt.Fprintf(&buf, "\" %s\n", line)
+	})
+	fmt.Fprintf(&buf, "\" Generated by tcolors\n\n")
+	fmt.Fprintf(&buf, "set background=%s\n", s.vimBackground())
+	fmt.Fprintf(&buf, "hi clear\n")
+	fmt.Fprintf(&buf, "if exists(\"syntax_on\")\n  syntax reset\nendif\n")
+	fmt.Fprintf(&buf, "let g:colors_name = \"%s\"\n\n", name)
+
+	fmt.Fprintf(&buf, "hi Normal guifg=%s guibg=%s ctermfg=%d ctermbg=%d gui=NONE cterm=NONE\n",
+		vimHex(fg), vimHex(s.background), xterm256(fg), xterm256(s.background))
+	s.eachVimGroup(func(group string, ss *subState) {
+		fmt.Fprintf(&buf, "hi %s guifg=%s ctermfg=%d\n", group, vimHex(ss), xterm256(ss))
+	})
+	buf.WriteString("\n")
+
+	s.eachVimTerminalColor(func(n int, ss *subState) {
+		fmt.Fprintf(&buf, "let g:terminal_color_%d = \"%s\"\n", n, vimHex(ss))
+	})
+
+	return buf.String()
+}
+
+// NvimString returns the current State as a lua colorscheme for neovim
+func (s *State) NvimString() string {
+	var buf bytes.Buffer
+	name := s.namespace()
+	fg := s.foreground()
+
+	fmt.Fprintf(&buf, "-- Name: %s\n", name)
+	s.eachMetaLine(func(line string) {
+		fmt.Fprintf(&buf, "-- %s\n", line)
+	})
+	fmt.Fprintf(&buf, "-- Generated by tcolors\n\n")
+	fmt.Fprintf(&buf, "vim.cmd(\"highlight clear\")\n")
+	fmt.Fprintf(&buf, "if vim.fn.exists(\"syntax_on\") == 1 then\n  vim.cmd(\"syntax reset\")\nend\n")
+	fmt.Fprintf(&buf, "vim.o.background = \"%s\"\n", s.vimBackground())
+	fmt.Fprintf(&buf, "vim.g.colors_name = \"%s\"\n\n", name)
+
+	fmt.Fprintf(&buf, "local hl = vim.api.nvim_set_hl\n")
+	fmt.Fprintf(&buf, "hl(0, \"Normal\", { fg = \"%s\", bg = \"%s\", ctermfg = %d, ctermbg = %d })\n",
+		vimHex(fg), vimHex(s.background), xterm256(fg), xterm256(s.background))
+	s.eachVimGroup(func(group string, ss *subState) {
+		fmt.Fprintf(&buf, "hl(0, \"%s\", { fg = \"%s\", ctermfg = %d })\n", group, vimHex(ss), xterm256(ss))
+	})
+	buf.WriteString("\n")
+
+	s.eachVimTerminalColor(func(n int, ss *subState) {
+		fmt.Fprintf(&buf, "vim.g.terminal_color_%d = \"%s\"\n", n, vimHex(ss))
+	})
+
+	return buf.String()
+}
+
+// vimGroupMap returns the default highlight group mapping, overridden by
+// any mapping given in the palette file. Default indices beyond the palette
+// length wrap around; groups mapped by the palette file to a color it does
+// not have are skipped with a warning.
+func (s *State) vimGroupMap() map[string]int {
+	groups := make(map[string]int, len(defaultVimGroups)+len(s.vimGroups))
+	for group, idx := range defaultVimGroups {
+		groups[group] = idx % s.Len()
+	}
+	for group, idx := range s.vimGroups {
+		if idx >= s.Len() {
+			log.Warningf("[vim] skipping highlight group %s: no color %d in palette of %d colors", group, idx, s.Len())
+			delete(groups, group)
+			continue
+		}
+		groups[group] = idx
+	}
+	return groups
+}
+
+// eachVimGroup calls fn with each highlight group in sorted order, along with
+// its mapped palette color
+func (s *State) eachVimGroup(fn func(string, *subState)) {
+	groups := s.vimGroupMap()
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	for _, group := range names {
+		fn(group, s.sstates[groups[group]])
+	}
+}
+
+// eachVimTerminalColor calls fn with each of the 16 terminal colors, repeating
+// palette colors where the palette has fewer than 16
+func (s *State) eachVimTerminalColor(fn func(int, *subState)) {
+	for n := 0; n < vimTerminalColors; n++ {
+		fn(n, s.sstates[n%s.Len()])
+	}
+}
+
+func (s *State) vimBackground() string {
+	if s.background.Luminance() > 0.5 {
+		return "light"
+	}
+	return "dark"
+}
+
+// validVimGroups returns an error if any highlight group in the given mapping
+// has a negative color index
+func validVimGroups(groups map[string]int) error {
+	for group, idx := range groups {
+		if idx < 0 {
+			return fmt.Errorf("highlight group %s: invalid color index %d", group, idx)
+		}
+	}
+	return nil
+}
+
+func vimHex(ss *subState) string {
+	return "#" + strings.ToLower(ss.Hex())
+}
+
+// xterm256 returns the index of the xterm-256 color nearest to ss, from the
+// 6x6x6 color cube and grayscale ramp
+func xterm256(ss *subState) int {
+	r, g, b := ss.RGB()
+
+	cubeIdx := func(n float64) int {
+		if n < 48 {
+			return 0
+		}
+		if n < 115 {
+			return 1
+		}
+		return int((n - 35) / 40)
+	}
+	cubeVal := func(i int) float64 {
+		if i == 0 {
+			return 0
+		}
+		return float64(55 + i*40)
+	}
+
+	ri, gi, bi := cubeIdx(r), cubeIdx(g), cubeIdx(b)
+	cr, cg, cb := cubeVal(ri), cubeVal(gi), cubeVal(bi)
+
+	grayIdx := int((r+g+b)/3-3) / 10
+	if grayIdx < 0 {
+		grayIdx = 0
+	}
+	if grayIdx > 23 {
+		grayIdx = 23
+	}
+	gv := float64(8 + grayIdx*10)
+
+	dist := func(x, y, z float64) float64 {
+		return (r-x)*(r-x) + (g-y)*(g-y) + (b-z)*(b-z)
+	}
+
+	if dist(gv, gv, gv) < dist(cr, cg, cb) {
+		return 232 + grayIdx
+	}
+	return 16 + 36*ri + 6*gi + bi
+}
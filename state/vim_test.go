@@ -0,0 +1,125 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestVimGroupMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		colors  int
+		groups  map[string]int
+		want    map[string]int
+		missing []string
+	}{
+		{name: "defaults", colors: 7, want: map[string]int{"Comment": 6, "Type": 4, "Error": 0}},
+		{name: "wrapped defaults", colors: 4, want: map[string]int{"Comment": 2, "Type": 0, "Identifier": 1}},
+		{name: "override", colors: 7, groups: map[string]int{"Comment": 2, "Title": 3}, want: map[string]int{"Comment": 2, "Title": 3, "Type": 4}},
+		{name: "out of palette", colors: 3, groups: map[string]int{"Comment": 5, "Title": 3}, missing: []string{"Comment", "Title"}},
+	}
+
+	for _, tt := range tests {
+		var colors [][]int
+		for n := 0; n < tt.colors; n++ {
+			colors = append(colors, []int{n, n, n})
+		}
+		s := testState(t, []int{0, 0, 0}, colors...)
+		s.vimGroups = tt.groups
+
+		got := s.vimGroupMap()
+		for group, idx := range tt.want {
+			if n, ok := got[group]; !ok || n != idx {
+				t.Errorf("%s: %s = %d, %v, want %d", tt.name, group, n, ok, idx)
+			}
+		}
+		for _, group := range tt.missing {
+			if _, ok := got[group]; ok {
+				t.Errorf("%s: unexpected group %s", tt.name, group)
+			}
+		}
+	}
+}
+
+func TestValidVimGroups(t *testing.T) {
+	tests := []struct {
+		groups  map[string]int
+		wantErr bool
+	}{
+		{groups: nil},
+		{groups: map[string]int{"Comment": 0, "Title": 15}},
+		{groups: map[string]int{"Comment": -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validVimGroups(tt.groups); (err != nil) != tt.wantErr {
+			t.Errorf("validVimGroups(%v) = %v, want error %v", tt.groups, err, tt.wantErr)
+		}
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	tests := []struct {
+		rgb  []int
+		want int
+	}{
+		{[]int{0, 0, 0}, 16},
+		{[]int{255, 255, 255}, 231},
+		{[]int{255, 0, 0}, 196},
+		{[]int{0, 0, 255}, 21},
+		{[]int{128, 128, 128}, 244},
+		{[]int{8, 8, 8}, 232},
+	}
+
+	for _, tt := range tests {
+		s := testState(t, []int{0, 0, 0}, tt.rgb)
+		if got := xterm256(s.sstates[0]); got != tt.want {
+			t.Errorf("xterm256(%v) = %d, want %d", tt.rgb, got, tt.want)
+		}
+	}
+}
+
+func TestVimOutputs(t *testing.T) {
+	s := testState(t, []int{255, 255, 255}, []int{255, 0, 0}, []int{0, 0, 255})
+	s.name = "My Palette"
+	s.meta.Author = "A. Author"
+
+	tests := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{"vim", s.VimString(), []string{
+			"\" Name: my-palette\n",
+			"\" Author: A. Author\n",
+			"set background=light\n",
+			"let g:colors_name = \"my-palette\"\n",
+			"hi Comment guifg=#ff0000 ctermfg=196\n",
+			"hi Type guifg=#ff0000 ctermfg=196\n",
+			"hi Statement guifg=#0000ff ctermfg=21\n",
+			"let g:terminal_color_15 = \"#0000ff\"\n",
+		}},
+		{"nvim", s.NvimString(), []string{
+			"-- Name: my-palette\n",
+			"-- Author: A. Author\n",
+			"vim.o.background = \"light\"\n",
+			"vim.g.colors_name = \"my-palette\"\n",
+			"hl(0, \"Comment\", { fg = \"#ff0000\", ctermfg = 196 })\n",
+			"vim.g.terminal_color_15 = \"#0000ff\"\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, line := range tt.want {
+			if !strings.Contains(tt.out, line) {
+				t.Errorf("%s: missing %q in\n%s", tt.name, line, tt.out)
+			}
+		}
+		for n := 0; n < vimTerminalColors; n++ {
+			if !strings.Contains(tt.out, fmt.Sprintf("terminal_color_%d ", n)) {
+				t.Errorf("%s: missing terminal color %d", tt.name, n)
+			}
+		}
+	}
+}
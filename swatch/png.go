@@ -0,0 +1,46 @@
+package swatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// EncodePNG writes sh to w as a PNG image
+func EncodePNG(w io.Writer, sh Sheet) error {
+	l := sh.layout()
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{sh.Background}, image.Point{}, draw.Src)
+
+	drawText(img, margin, l.titleY, sh.Title, titleScale, sh.Foreground)
+
+	for n, c := range sh.Chips {
+		pos := l.chips[n]
+		rect := image.Rect(pos.x, pos.y, pos.x+chipSize, pos.y+chipSize)
+		draw.Draw(img, rect, &image.Uniform{c.Color}, image.Point{}, draw.Src)
+		for i, label := range c.Labels {
+			drawText(img, pos.x, pos.labelY+i*lineHeight, label, labelScale, sh.Foreground)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// drawText draws s with the builtin bitmap font, top left at x, y
+func drawText(img *image.RGBA, x, y int, s string, scale int, c color.RGBA) {
+	for _, r := range s {
+		g := glyph(r)
+		for row := 0; row < glyphH; row++ {
+			for col := 0; col < glyphW; col++ {
+				if g[row]&(1<<uint(glyphW-1-col)) == 0 {
+					continue
+				}
+				px := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(img, px, &image.Uniform{c}, image.Point{}, draw.Src)
+			}
+		}
+		x += (glyphW + 1) * scale
+	}
+}
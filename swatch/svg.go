@@ -0,0 +1,49 @@
+package swatch
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+const svgFont = "monospace"
+
+// EncodeSVG writes sh to w as an SVG image
+func EncodeSVG(w io.Writer, sh Sheet) error {
+	l := sh.layout()
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(&buf, "  <rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", svgColor(sh.Background))
+	writeSVGText(&buf, margin, l.titleY, sh.Title, titleScale, sh.Foreground)
+
+	for n, c := range sh.Chips {
+		pos := l.chips[n]
+		fmt.Fprintf(&buf, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+			pos.x, pos.y, chipSize, chipSize, svgColor(c.Color))
+		for i, label := range c.Labels {
+			writeSVGText(&buf, pos.x, pos.labelY+i*lineHeight, label, labelScale, sh.Foreground)
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeSVGText writes a text element sized to match the bitmap font at the
+// given scale, top left at x, y
+func writeSVGText(buf *bytes.Buffer, x, y int, s string, scale int, c color.RGBA) {
+	size := glyphH * scale * 10 / 7 // cap height ~70% of font size
+	fmt.Fprintf(buf, "  <text x=\"%d\" y=\"%d\" font-family=\"%s\" font-size=\"%d\" fill=\"%s\">",
+		x, y+glyphH*scale, svgFont, size, svgColor(c))
+	xml.EscapeText(buf, []byte(s))
+	buf.WriteString("</text>\n")
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
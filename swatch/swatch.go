@@ -0,0 +1,76 @@
+// Package swatch renders palettes as swatch sheet images
+package swatch
+
+import "image/color"
+
+// sheet layout, in pixels
+const (
+	margin     = 32
+	chipSize   = 128
+	gap        = 32
+	maxColumns = 4
+	titleScale = 3
+	labelScale = 2
+	lineHeight = glyphH*labelScale + 6
+)
+
+// Chip is a single labelled color swatch
+type Chip struct {
+	Color  color.RGBA
+	Labels []string // lines of text drawn below the swatch
+}
+
+// Sheet is a titled set of color swatches drawn on a background
+type Sheet struct {
+	Title      string
+	Background color.RGBA
+	Foreground color.RGBA // text color
+	Chips      []Chip
+}
+
+// layout holds the computed dimensions and positions of a Sheet
+type layout struct {
+	width, height int
+	titleY        int
+	chips         []chipPos
+}
+
+type chipPos struct {
+	x, y   int // top left of swatch
+	labelY int // baseline top of first label line
+}
+
+func (sh *Sheet) layout() layout {
+	cols := len(sh.Chips)
+	if cols > maxColumns {
+		cols = maxColumns
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	rows := (len(sh.Chips) + cols - 1) / cols
+
+	var labelLines int
+	for _, c := range sh.Chips {
+		if len(c.Labels) > labelLines {
+			labelLines = len(c.Labels)
+		}
+	}
+	cellH := chipSize + 8 + labelLines*lineHeight
+
+	l := layout{
+		width:  margin*2 + cols*chipSize + (cols-1)*gap,
+		titleY: margin,
+	}
+	top := margin + glyphH*titleScale + gap
+	l.height = top + rows*cellH + (rows-1)*gap + margin
+
+	for n := range sh.Chips {
+		col, row := n%cols, n/cols
+		x := margin + col*(chipSize+gap)
+		y := top + row*(cellH+gap)
+		l.chips = append(l.chips, chipPos{x, y, y + chipSize + 8})
+	}
+
+	return l
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bcicen/tcolors/state"
+	"github.com/bcicen/tcolors/styles"
+	"github.com/gdamore/tcell"
+)
+
+const tabSep = " │ "
+
+// openTabs returns the palettes open in each tab
+func (d *Display) openTabs() []*state.State {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return append([]*state.State(nil), d.tabs...)
+}
+
+// activeTab returns the palette displayed in the active tab
+func (d *Display) activeTab() *state.State {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.state
+}
+
+// dirtyTabs returns the open palettes with unsaved changes
+func (d *Display) dirtyTabs() []*state.State {
+	var dirty []*state.State
+	for _, st := range d.openTabs() {
+		if st.Dirty() {
+			dirty = append(dirty, st)
+		}
+	}
+	return dirty
+}
+
+// findTab returns the index of the tab with the palette stored at path
+func (d *Display) findTab(path string) (int, bool) {
+	for n, st := range d.openTabs() {
+		if state.SamePath(st.Path(), path) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// isOpen returns whether the palette stored at path is open in any tab
+func (d *Display) isOpen(path string) bool {
+	_, ok := d.findTab(path)
+	return ok
+}
+
+// SwitchTab makes the palette in tab n the displayed palette, wrapping
+// around at either end
+func (d *Display) SwitchTab(n int) {
+	count := len(d.openTabs())
+	if count < 2 {
+		return
+	}
+	d.showTab((n%count + count) % count)
+}
+
+// showTab displays the palette in tab n, with new sections pointing at it
+func (d *Display) showTab(n int) {
+	d.lock.Lock()
+	d.tab = n
+	d.state = d.tabs[n]
+	d.sections = d.newSections()
+	d.dragN = -1
+	d.lock.Unlock()
+
+	for _, sec := range d.sections {
+		sec.Handle(state.AllChanged)
+	}
+	d.build()
+	d.watchPalette()
+	d.checkTab()
+
+	// resize from the event loop, the palette size may differ
+	w, h := d.screen.Size()
+	d.screen.PostEvent(tcell.NewEventResize(w, h))
+}
+
+// OpenTab opens the palette stored at path in a new tab, or switches to
+// the tab it is already open in
+func (d *Display) OpenTab(path string) error {
+	if n, ok := d.findTab(path); ok {
+		d.SwitchTab(n)
+		return nil
+	}
+
+	st, err := state.Load(path)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	d.tabs = append(d.tabs, st)
+	n := len(d.tabs) - 1
+	d.lock.Unlock()
+
+	d.showTab(n)
+	return nil
+}
+
+// CloseTab closes the active tab, refusing if it has unsaved changes unless
+// discard is set. The last tab may not be closed.
+func (d *Display) CloseTab(discard bool) error {
+	if len(d.openTabs()) < 2 {
+		return fmt.Errorf("cannot close last tab (exit with :q)")
+	}
+	if d.state.Dirty() && !discard {
+		return fmt.Errorf("unsaved changes (save with :w or discard with :tabc!)")
+	}
+	if discard {
+		d.state.RemoveRecovery()
+	}
+	d.state.Close()
+
+	d.lock.Lock()
+	d.tabs = append(d.tabs[:d.tab], d.tabs[d.tab+1:]...)
+	n := d.tab
+	if n == len(d.tabs) {
+		n--
+	}
+	d.lock.Unlock()
+
+	d.showTab(n)
+	return nil
+}
+
+// checkTab reports on the palette of a newly displayed tab: whether it is
+// read-only, has recoverable changes, or was changed on disk while another
+// tab was displayed
+func (d *Display) checkTab() {
+	if d.state.ReadOnly() {
+		d.errMsg.Set(fmt.Sprintf("palette in use by pid %s, opened read-only", d.state.LockHolder()))
+	} else {
+		d.offerRecovery()
+	}
+	if !d.prompt.Active() {
+		d.handlePaletteChange()
+	}
+}
+
+// drawTabs draws the name of each open palette right-aligned in the header
+// at the given row, highlighting the active tab. Names are shortened to fit
+// where needed.
+func (d *Display) drawTabs(x, y int, s tcell.Screen) {
+	labels := make([][]rune, len(d.tabs))
+	width := len([]rune(tabSep)) * (len(d.tabs) - 1)
+	for n, st := range d.tabs {
+		label := st.Name()
+		if st.ReadOnly() {
+			label += " [RO]"
+		}
+		if st.Dirty() {
+			label += " [+]"
+		}
+		labels[n] = []rune(label)
+		width += len(labels[n])
+	}
+
+	// shorten labels evenly to fit beside the step indicator
+	avail := d.width - 2
+	if width > avail {
+		maxW := (avail-len([]rune(tabSep))*(len(d.tabs)-1))/len(d.tabs) - 1
+		if maxW < 1 {
+			maxW = 1
+		}
+		width = len([]rune(tabSep)) * (len(d.tabs) - 1)
+		for n, label := range labels {
+			if len(label) > maxW {
+				labels[n] = append(label[:maxW-1:maxW-1], '…')
+			}
+			width += len(labels[n])
+		}
+	}
+
+	d.tabX = make([][2]int, len(d.tabs))
+	col := x + d.width - width
+	for n, label := range labels {
+		if n > 0 {
+			for _, ch := range tabSep {
+				s.SetCell(col, y, styles.Indicator, ch)
+				col++
+			}
+		}
+		st := styles.TextBox
+		if n == d.tab && len(d.tabs) > 1 {
+			st = styles.IndicatorHi
+		}
+		d.tabX[n] = [2]int{col, col + len(label)}
+		for _, ch := range label {
+			s.SetCell(col, y, st, ch)
+			col++
+		}
+	}
+}
+
+// Yank copies the selected color, for pasting into any open palette
+func (d *Display) Yank() (ok bool) {
+	clip := d.state.Yank()
+	d.clip = &clip
+	d.errMsg.Set(fmt.Sprintf("yanked #%s", clip.HexString()))
+	return true
+}
+
+// Paste replaces the selected color with the last yanked color
+func (d *Display) Paste() (ok bool) {
+	if d.clip == nil {
+		d.errMsg.Set("nothing yanked")
+		return true
+	}
+	if err := d.state.Paste(*d.clip); err != nil {
+		d.errMsg.Set(err.Error())
+		return true
+	}
+	d.build()
+	d.errMsg.Set(fmt.Sprintf("pasted #%s", d.clip.HexString()))
+	return true
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bcicen/tcolors/state"
+)
+
+// runValidate implements the validate subcommand, reporting any problems
+// found in a palette file
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fileFlag := fs.String("f", state.DefaultPalettePath, "palette file to validate")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: tcolors validate [-f PALETTE_FILE]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	problems, err := state.Validate(*fileFlag)
+	errExit(err)
+
+	if len(problems) == 0 {
+		fmt.Printf("%s: ok\n", *fileFlag)
+		return
+	}
+	for _, p := range problems {
+		if p.Line > 0 {
+			fmt.Printf("%s:%d: %s\n", *fileFlag, p.Line, p.Msg)
+		} else {
+			fmt.Printf("%s: %s\n", *fileFlag, p.Msg)
+		}
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,73 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// events indicating a file has been completely written or renamed into
+// place, as on an atomic save
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO
+
+type sysWatcher struct {
+	f      *os.File // inotify descriptor, nil if polling
+	target string   // watched file, with symlinks resolved
+}
+
+// start watches the parent directory of path with inotify, such that the
+// file continues to be watched when replaced by rename. A symlinked path is
+// watched at its target, where saves are written. The file is polled for
+// changes if inotify is unavailable.
+func (w *Watcher) start() {
+	w.sys.target = w.path
+	if target, err := filepath.EvalSymlinks(w.path); err == nil {
+		w.sys.target = target
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		go w.poll()
+		return
+	}
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(w.sys.target), inotifyMask); err != nil {
+		syscall.Close(fd)
+		go w.poll()
+		return
+	}
+
+	// a non-blocking descriptor is pollable, allowing Close to interrupt Read
+	w.sys.f = os.NewFile(uintptr(fd), "inotify")
+	go w.read()
+}
+
+func (w *Watcher) stop() {
+	if w.sys.f != nil {
+		w.sys.f.Close()
+	}
+}
+
+func (w *Watcher) read() {
+	base := filepath.Base(w.sys.target)
+	buf := make([]byte, 4096)
+
+	for {
+		n, err := w.sys.f.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			end := start + int(ev.Len)
+			name := strings.TrimRight(string(buf[start:end]), "\x00")
+			if name == base {
+				w.notify()
+			}
+			offset = end
+		}
+	}
+}
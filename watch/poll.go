@@ -0,0 +1,29 @@
+package watch
+
+import (
+	"os"
+	"time"
+)
+
+const pollInterval = time.Second
+
+// poll checks the file for changes to its modification time or size
+func (w *Watcher) poll() {
+	last, _ := os.Stat(w.path)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-time.After(pollInterval):
+		}
+
+		fi, err := os.Stat(w.path)
+		if err != nil {
+			continue
+		}
+		if last == nil || !fi.ModTime().Equal(last.ModTime()) || fi.Size() != last.Size() {
+			w.notify()
+		}
+		last = fi
+	}
+}
@@ -0,0 +1,49 @@
+// Package watch provides notification of changes to a file
+package watch
+
+import "path/filepath"
+
+// Watcher sends on C when the watched file is written or replaced. Multiple
+// changes before C is read are coalesced into a single notification.
+type Watcher struct {
+	C    chan struct{}
+	path string
+	done chan struct{}
+	sys  sysWatcher // platform specific state
+}
+
+// New returns a Watcher for the file at path. The file need not exist;
+// changes are reported once it is created.
+func New(path string) (*Watcher, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		C:    make(chan struct{}, 1),
+		path: abs,
+		done: make(chan struct{}),
+	}
+	w.start()
+	return w, nil
+}
+
+// Close stops watching for changes
+func (w *Watcher) Close() {
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+		w.stop()
+	}
+}
+
+// Closed returns a channel closed once the Watcher is closed
+func (w *Watcher) Closed() <-chan struct{} { return w.done }
+
+func (w *Watcher) notify() {
+	select {
+	case w.C <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package watch
+
+type sysWatcher struct{}
+
+func (w *Watcher) start() { go w.poll() }
+
+func (w *Watcher) stop() {}
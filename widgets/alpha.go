@@ -0,0 +1,82 @@
+package widgets
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bcicen/tcolors/state"
+	"github.com/gdamore/tcell"
+)
+
+const (
+	alphaMin   = 0.0
+	alphaMax   = 100.0
+	alphaIncr  = 0.5
+	alphaCount = int(alphaMax/alphaIncr) + 1
+)
+
+// AlphaBar selects the opacity of the current color, previewed over the
+// palette background
+type AlphaBar struct {
+	*NavBar
+	scale [alphaCount]float64
+}
+
+func NewAlphaBar(s *state.State) *AlphaBar {
+	bar := &AlphaBar{NavBar: NewNavBar(s, alphaCount)}
+
+	i := alphaMin
+	for n := range bar.scale {
+		bar.scale[n] = i
+		i += alphaIncr
+	}
+
+	return bar
+}
+
+// Draw redraws bar at given coordinates and screen, below a spacing row,
+// returning the number of rows occupied
+func (bar *AlphaBar) Draw(x, y int, s tcell.Screen) int {
+	h := bar.NavBar.Draw(x, y+1, s)
+	return h + 1
+}
+
+// State change handler
+func (bar *AlphaBar) Handle(change state.Change) {
+	if change.Includes(state.HueChanged, state.SaturationChanged, state.ValueChanged) {
+		r, g, b := bar.state.Selected().RGB()
+		br, bg, bb := bar.state.Background().RGB()
+		for n, val := range bar.scale {
+			a := val / alphaMax
+			mix := func(fg float64, bg int32) int32 {
+				return int32(math.Round(fg*a + float64(bg)*(1-a)))
+			}
+			bar.items[n] = tcell.NewRGBColor(mix(r, br), mix(g, bg), mix(b, bb))
+		}
+	}
+
+	if change.Includes(state.AlphaChanged) {
+		bar.SetPos(roundFloat(bar.state.Alpha() / alphaIncr))
+		bar.SetLabel(fmt.Sprintf("%5.1f ", bar.scale[bar.pos]))
+	}
+}
+
+func (bar *AlphaBar) Up(step int) {
+	bar.up(step)
+	bar.setState()
+}
+
+func (bar *AlphaBar) Down(step int) {
+	bar.down(step)
+	bar.setState()
+}
+
+// Click sets the alpha at the given screen column
+func (bar *AlphaBar) Click(x, y int) {
+	bar.SetPos(bar.itemAt(x))
+	bar.setState()
+}
+
+func (bar *AlphaBar) setState() {
+	bar.state.SetAlpha(bar.scale[bar.pos])
+}
@@ -0,0 +1,172 @@
+package widgets
+
+import (
+	"github.com/bcicen/tcolors/styles"
+	"github.com/gdamore/tcell"
+)
+
+const cmdPrompt = ":"
+
+// CompleteFn returns candidate completions for the given input line
+type CompleteFn func(string) []string
+
+// CommandLine is a single line command prompt with history and tab
+// completion
+type CommandLine struct {
+	active   bool
+	prompt   []rune
+	input    []rune
+	cursor   int
+	history  []string
+	histPos  int
+	complete CompleteFn
+	cands    []string // current completion candidates
+	candPos  int
+	width    int
+}
+
+func NewCommandLine(complete CompleteFn) *CommandLine {
+	return &CommandLine{complete: complete}
+}
+
+// Open activates the command line with empty input
+func (cl *CommandLine) Open() {
+	cl.OpenWith(cmdPrompt, "")
+}
+
+// OpenWith activates the command line with the given prompt and initial
+// input, for use as a general text input
+func (cl *CommandLine) OpenWith(prompt, input string) {
+	cl.active = true
+	cl.prompt = []rune(prompt)
+	cl.input = []rune(input)
+	cl.cursor = len(cl.input)
+	cl.histPos = len(cl.history)
+	cl.cands = nil
+}
+
+func (cl *CommandLine) Active() bool { return cl.active }
+
+// HandleKey processes a key event while the command line is active. When
+// input is submitted, the entered line is returned with done set.
+func (cl *CommandLine) HandleKey(ev *tcell.EventKey) (line string, done bool) {
+	if ev.Key() != tcell.KeyTab {
+		cl.cands = nil
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		cl.active = false
+		line = string(cl.input)
+		if line != "" {
+			cl.history = append(cl.history, line)
+		}
+		return line, true
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		cl.active = false
+		return "", true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if cl.cursor == 0 {
+			if len(cl.input) == 0 {
+				cl.active = false
+				return "", true
+			}
+			break
+		}
+		cl.input = append(cl.input[:cl.cursor-1], cl.input[cl.cursor:]...)
+		cl.cursor--
+	case tcell.KeyDelete:
+		if cl.cursor < len(cl.input) {
+			cl.input = append(cl.input[:cl.cursor], cl.input[cl.cursor+1:]...)
+		}
+	case tcell.KeyLeft:
+		if cl.cursor > 0 {
+			cl.cursor--
+		}
+	case tcell.KeyRight:
+		if cl.cursor < len(cl.input) {
+			cl.cursor++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		cl.cursor = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		cl.cursor = len(cl.input)
+	case tcell.KeyCtrlU:
+		cl.input = cl.input[cl.cursor:]
+		cl.cursor = 0
+	case tcell.KeyUp:
+		if cl.histPos > 0 {
+			cl.histPos--
+			cl.setInput(cl.history[cl.histPos])
+		}
+	case tcell.KeyDown:
+		if cl.histPos < len(cl.history)-1 {
+			cl.histPos++
+			cl.setInput(cl.history[cl.histPos])
+		} else {
+			cl.histPos = len(cl.history)
+			cl.setInput("")
+		}
+	case tcell.KeyTab:
+		cl.completeNext()
+	case tcell.KeyRune:
+		cl.input = append(cl.input[:cl.cursor], append([]rune{ev.Rune()}, cl.input[cl.cursor:]...)...)
+		cl.cursor++
+	}
+
+	return "", false
+}
+
+// completeNext replaces the input with the next completion candidate,
+// cycling through candidates on repeated calls
+func (cl *CommandLine) completeNext() {
+	if cl.complete == nil {
+		return
+	}
+	if cl.cands == nil {
+		cl.cands = cl.complete(string(cl.input))
+		cl.candPos = 0
+		if len(cl.cands) == 0 {
+			return
+		}
+	} else {
+		cl.candPos = (cl.candPos + 1) % len(cl.cands)
+	}
+	cl.setInput(cl.cands[cl.candPos])
+}
+
+func (cl *CommandLine) setInput(s string) {
+	cl.input = []rune(s)
+	cl.cursor = len(cl.input)
+}
+
+// Draw redraws the command line on the last screen row, if active
+func (cl *CommandLine) Draw(x int, s tcell.Screen) {
+	if !cl.active {
+		return
+	}
+	_, h := s.Size()
+	y := h - 1
+
+	for i := x; i <= x+cl.width; i++ {
+		s.SetCell(i, y, styles.TextBox, ' ')
+	}
+	drawText(s, x, y, styles.TextBox, string(cl.prompt))
+	x += len(cl.prompt)
+	width := cl.width - len(cl.prompt) + 1
+
+	// scroll input to keep cursor visible
+	start := 0
+	if cl.cursor >= width-1 {
+		start = cl.cursor - width + 2
+	}
+	for n, ch := range cl.input[start:] {
+		if n+1 > width {
+			break
+		}
+		s.SetCell(x+n, y, styles.TextBox, ch)
+	}
+	s.ShowCursor(x+cl.cursor-start, y)
+}
+
+func (cl *CommandLine) Resize(w int) { cl.width = w }
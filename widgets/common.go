@@ -21,3 +21,31 @@ func toTColor(c *noire.Color) tcell.Color {
 	r, g, b := c.RGB()
 	return tcell.NewRGBColor(int32(r), int32(g), int32(b))
 }
+
+// drawText draws text one rune per cell starting at the given coordinates
+func drawText(s tcell.Screen, x, y int, st tcell.Style, text string) {
+	for n, ch := range []rune(text) {
+		s.SetCell(x+n, y, st, ch)
+	}
+}
+
+// wrap returns n wrapped to the range 0 to length-1
+func wrap(n, length int) int {
+	n = n % length
+	if n < 0 {
+		n += length
+	}
+	return n
+}
+
+// clamp returns n limited to the range min to max
+func clamp(n, min, max int) int {
+	switch {
+	case n < min:
+		return min
+	case n > max:
+		return max
+	default:
+		return n
+	}
+}
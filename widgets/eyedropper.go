@@ -0,0 +1,217 @@
+package widgets
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/bcicen/tcolors/styles"
+	"github.com/gdamore/tcell"
+)
+
+const (
+	maxSampleSize = 9
+	bigMove       = 10
+)
+
+// Eyedropper renders an image using half-block cells and allows a color to
+// be sampled from it with a movable crosshair
+type Eyedropper struct {
+	img    image.Image
+	pixels [][]tcell.Color // scaled image, two pixel rows per screen row
+	x, y   int             // crosshair position, in scaled pixels
+	offX   int
+	offY   int
+	sample int // sample area size, NxN pixels
+	onPick func(tcell.Color)
+}
+
+// NewEyedropper returns an Eyedropper for img, calling onPick with the
+// sampled color when a color is chosen
+func NewEyedropper(img image.Image, onPick func(tcell.Color)) *Eyedropper {
+	return &Eyedropper{img: img, sample: 1, onPick: onPick}
+}
+
+// Menu implements MenuFn, running the eyedropper until a color is picked
+// or the eyedropper is cancelled
+func (ed *Eyedropper) Menu(s tcell.Screen) MenuFn {
+	ed.resize(s.Size())
+	for {
+		ed.draw(s)
+
+		switch ev := s.PollEvent().(type) {
+		case *tcell.EventKey:
+			step := 1
+			if ev.Modifiers()&tcell.ModShift == tcell.ModShift {
+				step = bigMove
+			}
+			switch ev.Key() {
+			case tcell.KeyEnter:
+				ed.onPick(ed.Sampled())
+				return nil
+			case tcell.KeyEscape, tcell.KeyCtrlC:
+				return nil
+			case tcell.KeyLeft:
+				ed.move(-step, 0)
+			case tcell.KeyRight:
+				ed.move(step, 0)
+			case tcell.KeyUp:
+				ed.move(0, -step)
+			case tcell.KeyDown:
+				ed.move(0, step)
+			case tcell.KeyRune:
+				switch ev.Rune() {
+				case 'h':
+					ed.move(-1, 0)
+				case 'l':
+					ed.move(1, 0)
+				case 'k':
+					ed.move(0, -1)
+				case 'j':
+					ed.move(0, 1)
+				case 'H':
+					ed.move(-bigMove, 0)
+				case 'L':
+					ed.move(bigMove, 0)
+				case 'K':
+					ed.move(0, -bigMove)
+				case 'J':
+					ed.move(0, bigMove)
+				case '+':
+					if ed.sample < maxSampleSize {
+						ed.sample += 2
+					}
+				case '-':
+					if ed.sample > 1 {
+						ed.sample -= 2
+					}
+				case 'q':
+					return nil
+				}
+			}
+		case *tcell.EventMouse:
+			if ev.Buttons()&tcell.Button1 != 0 {
+				mx, my := ev.Position()
+				ed.x = mx - ed.offX
+				ed.y = (my - ed.offY) * 2
+				ed.move(0, 0)
+			}
+		case *tcell.EventResize:
+			s.Clear()
+			ed.resize(s.Size())
+		}
+	}
+}
+
+// Sampled returns the average color of the sample area centered on the
+// crosshair
+func (ed *Eyedropper) Sampled() tcell.Color {
+	var r, g, b, count int32
+	half := ed.sample / 2
+	for y := ed.y - half; y <= ed.y+half; y++ {
+		for x := ed.x - half; x <= ed.x+half; x++ {
+			if y < 0 || y >= len(ed.pixels) || x < 0 || x >= len(ed.pixels[y]) {
+				continue
+			}
+			pr, pg, pb := ed.pixels[y][x].RGB()
+			r, g, b = r+pr, g+pg, b+pb
+			count++
+		}
+	}
+	if count == 0 {
+		return tcell.ColorDefault
+	}
+	return tcell.NewRGBColor(r/count, g/count, b/count)
+}
+
+func (ed *Eyedropper) move(dx, dy int) {
+	if len(ed.pixels) == 0 {
+		return
+	}
+	ed.x = clamp(ed.x+dx, 0, len(ed.pixels[0])-1)
+	ed.y = clamp(ed.y+dy, 0, len(ed.pixels)-1)
+}
+
+// resize scales the image to fit the screen, leaving room for the status line
+func (ed *Eyedropper) resize(w, h int) {
+	bounds := ed.img.Bounds()
+	maxW, maxH := w, (h-2)*2
+	if maxW < 1 || maxH < 1 || bounds.Empty() {
+		ed.pixels = nil
+		return
+	}
+
+	// scale to fit, preserving aspect ratio
+	scale := float64(maxW) / float64(bounds.Dx())
+	if hScale := float64(maxH) / float64(bounds.Dy()); hScale < scale {
+		scale = hScale
+	}
+	pw := int(float64(bounds.Dx()) * scale)
+	ph := int(float64(bounds.Dy()) * scale)
+	if pw < 1 {
+		pw = 1
+	}
+	if ph < 1 {
+		ph = 1
+	}
+
+	ed.pixels = make([][]tcell.Color, ph)
+	for y := range ed.pixels {
+		ed.pixels[y] = make([]tcell.Color, pw)
+		for x := range ed.pixels[y] {
+			sx := bounds.Min.X + int(float64(x)/scale)
+			sy := bounds.Min.Y + int(float64(y)/scale)
+			r, g, b, _ := ed.img.At(sx, sy).RGBA()
+			ed.pixels[y][x] = tcell.NewRGBColor(int32(r>>8), int32(g>>8), int32(b>>8))
+		}
+	}
+
+	ed.offX = (w - pw) / 2
+	ed.offY = ((h - 2) - (ph+1)/2) / 2
+	ed.move(0, 0)
+}
+
+func (ed *Eyedropper) draw(s tcell.Screen) {
+	_, h := s.Size()
+
+	for row := 0; row*2 < len(ed.pixels); row++ {
+		for x := range ed.pixels[row*2] {
+			st := styles.Default.Foreground(ed.pixels[row*2][x])
+			if row*2+1 < len(ed.pixels) {
+				st = st.Background(ed.pixels[row*2+1][x])
+			}
+			s.SetCell(ed.offX+x, ed.offY+row, st, '▀')
+		}
+	}
+
+	// crosshair
+	if len(ed.pixels) > 0 {
+		cst := styles.Default.
+			Foreground(contrasting(ed.pixels[ed.y][ed.x])).
+			Background(ed.pixels[ed.y][ed.x])
+		s.SetCell(ed.offX+ed.x, ed.offY+ed.y/2, cst, '+')
+	}
+
+	// status line
+	sampled := ed.Sampled()
+	r, g, b := sampled.RGB()
+	status := fmt.Sprintf(" %02X%02X%02X  %03d %03d %03d  %dx%d  ", r, g, b, r, g, b, ed.sample, ed.sample)
+	help := "[enter] pick  [esc] cancel  [+/-] sample size"
+
+	for x := 0; x < 3; x++ {
+		s.SetCell(x, h-1, styles.Default.Background(sampled), ' ')
+	}
+	for n, ch := range []rune(status + help) {
+		s.SetCell(3+n, h-1, styles.TextBox, ch)
+	}
+
+	s.Show()
+}
+
+// contrasting returns black or white, whichever is more legible on c
+func contrasting(c tcell.Color) tcell.Color {
+	r, g, b := c.RGB()
+	if (299*r+587*g+114*b)/1000 > 128 {
+		return tcell.ColorBlack
+	}
+	return tcell.ColorWhite
+}
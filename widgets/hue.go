@@ -21,6 +21,7 @@ type HueBar struct {
 	pos    int
 	width  int
 	height int
+	x, y   int // screen position, as of last draw
 	label  string
 	pst    tcell.Style // pointer style
 	state  *state.State
@@ -34,6 +35,7 @@ func (bar *HueBar) center() int      { return (bar.width / 2) }
 // Draw redraws bar at given coordinates and screen, returning the number
 // of rows occupied
 func (bar *HueBar) Draw(x, y int, s tcell.Screen) int {
+	bar.x, bar.y = x, y
 	center := bar.width / 2
 	st := styles.Default.Foreground(tcell.ColorBlack)
 
@@ -152,3 +154,24 @@ func (bar *HueBar) Down(step int) {
 }
 
 func (bar *HueBar) SetPointerStyle(st tcell.Style) { bar.pst = st }
+
+// HitTest reports whether the given screen coordinates fall within bar
+func (bar *HueBar) HitTest(x, y int) bool {
+	return x >= bar.x && x < bar.x+bar.width && y >= bar.y && y <= bar.y+bar.height+1
+}
+
+// Click sets the hue drawn at the given screen column, from either the main
+// bar or the minimap
+func (bar *HueBar) Click(x, y int) {
+	col := clamp(x-bar.x, 0, bar.width-1)
+
+	var idx int
+	if y == bar.y+bar.height {
+		midx := wrap(bar.miniPos()-bar.center()-1+col, len(bar.mItems))
+		idx = bar.mItems[midx]
+	} else {
+		idx = wrap(bar.pos-bar.center()+col, len(bar.items))
+	}
+
+	bar.state.SetHue(float64(idx) * hueIncr)
+}
@@ -0,0 +1,344 @@
+package widgets
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bcicen/tcolors/state"
+	"github.com/bcicen/tcolors/styles"
+	"github.com/gdamore/tcell"
+)
+
+const (
+	maxLibraryNameW = 30
+	libraryHelp     = "[enter] open  [n]ew  [d]uplicate  [r]ename  [x] delete  [q] close"
+)
+
+// LibraryBrowser lists the palettes in a directory with a swatch strip for
+// each, allowing palettes to be opened, created, duplicated, renamed and
+// deleted
+type LibraryBrowser struct {
+	dir     string
+	isOpen  func(path string) bool // whether a palette is open for editing
+	onOpen  func(path string) error
+	entries []state.LibraryEntry
+	pos     int
+	offset  int // first visible entry
+	msg     string
+	isErr   bool
+	input   *CommandLine
+	onInput func(string)
+	prompt  *Prompt
+}
+
+// NewLibraryBrowser returns a LibraryBrowser for the palettes in dir,
+// calling onOpen with the path of a palette chosen to be opened. isOpen
+// reports whether a palette is open for editing, in which case it may not
+// be renamed or deleted.
+func NewLibraryBrowser(dir string, isOpen func(string) bool, onOpen func(string) error) *LibraryBrowser {
+	return &LibraryBrowser{
+		dir:    dir,
+		isOpen: isOpen,
+		onOpen: onOpen,
+		input:  NewCommandLine(nil),
+		prompt: NewPrompt(),
+	}
+}
+
+// Menu implements MenuFn, running the browser until a palette is opened or
+// the browser is closed
+func (lb *LibraryBrowser) Menu(s tcell.Screen) MenuFn {
+	lb.refresh()
+	for {
+		lb.draw(s)
+
+		switch ev := s.PollEvent().(type) {
+		case *tcell.EventKey:
+			if lb.prompt.Active() {
+				lb.prompt.HandleKey(ev)
+				continue
+			}
+			if lb.input.Active() {
+				if line, done := lb.input.HandleKey(ev); done {
+					s.HideCursor()
+					if line != "" {
+						lb.onInput(line)
+					}
+				}
+				continue
+			}
+			if lb.handleKey(ev) {
+				return nil
+			}
+		case *tcell.EventMouse:
+			if ev.Buttons()&tcell.Button1 != 0 {
+				_, y := ev.Position()
+				if n := lb.offset + y - 3; y >= 3 && n < len(lb.entries) {
+					lb.pos = n
+				}
+			}
+		case *tcell.EventResize:
+			s.Clear()
+		}
+	}
+}
+
+// handleKey processes a key event, returning whether the browser should
+// be closed
+func (lb *LibraryBrowser) handleKey(ev *tcell.EventKey) (done bool) {
+	lb.msg = ""
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		return true
+	case tcell.KeyUp:
+		lb.move(-1)
+	case tcell.KeyDown:
+		lb.move(1)
+	case tcell.KeyEnter:
+		return lb.open()
+	case tcell.KeyDelete:
+		lb.delete()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return true
+		case 'k':
+			lb.move(-1)
+		case 'j':
+			lb.move(1)
+		case 'n':
+			lb.ask("new palette: ", "", lb.create)
+		case 'd':
+			if entry, ok := lb.selected(); ok {
+				base := strings.TrimSuffix(entry.Name, filepath.Ext(entry.Name))
+				lb.ask("duplicate as: ", base+"-copy"+filepath.Ext(entry.Name), lb.duplicate)
+			}
+		case 'r':
+			if entry, ok := lb.selected(); ok {
+				lb.ask("rename to: ", entry.Name, lb.rename)
+			}
+		case 'x':
+			lb.delete()
+		}
+	}
+	return false
+}
+
+// refresh reloads the list of palettes, keeping the selected position
+func (lb *LibraryBrowser) refresh() {
+	entries, err := state.Library(lb.dir)
+	if err != nil {
+		lb.setErr(err)
+	}
+	lb.entries = entries
+	lb.pos = clamp(lb.pos, 0, len(lb.entries)-1)
+}
+
+// selectPath selects the palette with the given path, if listed
+func (lb *LibraryBrowser) selectPath(path string) {
+	for n, entry := range lb.entries {
+		if entry.Path == path {
+			lb.pos = n
+		}
+	}
+}
+
+func (lb *LibraryBrowser) selected() (state.LibraryEntry, bool) {
+	if len(lb.entries) == 0 {
+		return state.LibraryEntry{}, false
+	}
+	return lb.entries[lb.pos], true
+}
+
+func (lb *LibraryBrowser) move(step int) {
+	if len(lb.entries) > 0 {
+		lb.pos = clamp(lb.pos+step, 0, len(lb.entries)-1)
+	}
+}
+
+// ask reads a palette name with the given prompt and initial input,
+// calling fn with the path of the named palette
+func (lb *LibraryBrowser) ask(prompt, input string, fn func(string) error) {
+	lb.onInput = func(name string) {
+		path, err := state.LibraryPath(lb.dir, name)
+		if err == nil {
+			err = fn(path)
+		}
+		if err != nil {
+			lb.setErr(err)
+		}
+	}
+	lb.input.OpenWith(prompt, input)
+}
+
+func (lb *LibraryBrowser) setErr(err error) {
+	lb.msg = err.Error()
+	lb.isErr = true
+}
+
+func (lb *LibraryBrowser) setMsg(format string, a ...interface{}) {
+	lb.msg = fmt.Sprintf(format, a...)
+	lb.isErr = false
+}
+
+func (lb *LibraryBrowser) open() (done bool) {
+	entry, ok := lb.selected()
+	if !ok {
+		return false
+	}
+	if err := lb.onOpen(entry.Path); err != nil {
+		lb.setErr(err)
+		return false
+	}
+	return true
+}
+
+func (lb *LibraryBrowser) create(path string) error {
+	if err := state.CreatePalette(path); err != nil {
+		return err
+	}
+	lb.refresh()
+	lb.selectPath(path)
+	lb.setMsg("created %s", filepath.Base(path))
+	return nil
+}
+
+func (lb *LibraryBrowser) duplicate(path string) error {
+	entry, _ := lb.selected()
+	if err := state.DuplicatePalette(entry.Path, path); err != nil {
+		return err
+	}
+	lb.refresh()
+	lb.selectPath(path)
+	lb.setMsg("duplicated %s as %s", entry.Name, filepath.Base(path))
+	return nil
+}
+
+func (lb *LibraryBrowser) rename(path string) error {
+	entry, _ := lb.selected()
+	if lb.isOpen(entry.Path) {
+		return fmt.Errorf("%s is open and may not be renamed", entry.Name)
+	}
+	if err := state.RenamePalette(entry.Path, path); err != nil {
+		return err
+	}
+	lb.refresh()
+	lb.selectPath(path)
+	lb.setMsg("renamed %s to %s", entry.Name, filepath.Base(path))
+	return nil
+}
+
+func (lb *LibraryBrowser) delete() {
+	entry, ok := lb.selected()
+	if !ok {
+		return
+	}
+	if lb.isOpen(entry.Path) {
+		lb.setErr(fmt.Errorf("%s is open and may not be deleted", entry.Name))
+		return
+	}
+
+	choices := []PromptChoice{
+		{Key: 'y', Label: "yes"},
+		{Key: 'n', Label: "no"},
+	}
+	lb.prompt.Ask(fmt.Sprintf("delete %s?", entry.Name), choices, func(key rune) {
+		if key != 'y' {
+			return
+		}
+		if err := state.DeletePalette(entry.Path); err != nil {
+			lb.setErr(err)
+			return
+		}
+		lb.refresh()
+		lb.setMsg("deleted %s (see tcolors restore)", entry.Name)
+	})
+}
+
+func (lb *LibraryBrowser) draw(s tcell.Screen) {
+	s.Clear()
+	w, h := s.Size()
+	lb.input.Resize(w - 2)
+	lb.prompt.Resize(w - 2)
+
+	drawText(s, 1, 1, styles.TextBox, "palettes in "+lb.dir)
+	if len(lb.entries) == 0 {
+		drawText(s, 1, 3, styles.Indicator, "no palettes (press n to create one)")
+	}
+
+	nameW := 0
+	for _, entry := range lb.entries {
+		if n := len([]rune(entry.Name)); n > nameW {
+			nameW = n
+		}
+	}
+	if nameW > maxLibraryNameW {
+		nameW = maxLibraryNameW
+	}
+
+	// scroll to keep the selected entry visible
+	rows := h - 6
+	if rows < 1 {
+		rows = 1
+	}
+	if lb.pos < lb.offset {
+		lb.offset = lb.pos
+	}
+	if lb.pos >= lb.offset+rows {
+		lb.offset = lb.pos - rows + 1
+	}
+
+	for n := lb.offset; n < len(lb.entries) && n < lb.offset+rows; n++ {
+		lb.drawEntry(s, lb.entries[n], n == lb.pos, 3+n-lb.offset, nameW)
+	}
+
+	drawText(s, 1, h-2, styles.Indicator, libraryHelp)
+	switch {
+	case lb.prompt.Active():
+		lb.prompt.Draw(1, s)
+	case lb.input.Active():
+		lb.input.Draw(1, s)
+	case lb.isErr:
+		drawText(s, 1, h-1, styles.Error, lb.msg)
+	default:
+		drawText(s, 1, h-1, styles.TextBox, lb.msg)
+	}
+
+	s.Show()
+}
+
+// drawEntry draws a single palette row: a selection marker, the file name
+// and a strip of the palette background followed by its colors
+func (lb *LibraryBrowser) drawEntry(s tcell.Screen, entry state.LibraryEntry, selected bool, y, nameW int) {
+	st := styles.TextBox
+	if selected {
+		st = styles.IndicatorHi
+		s.SetCell(1, y, st, '>')
+	}
+
+	name := []rune(entry.Name)
+	if len(name) > nameW {
+		name = append(name[:nameW-1], '…')
+	}
+	drawText(s, 3, y, st, string(name))
+	if lb.isOpen(entry.Path) {
+		s.SetCell(3+len(name), y, styles.Indicator, '*')
+	}
+
+	x := 5 + nameW
+	if entry.Err != nil {
+		drawText(s, x, y, styles.Error, entry.Err.Error())
+		return
+	}
+	// background, with a gap of background between it and the colors
+	bg := styles.Default.Background(entry.Background)
+	for col := 0; col < 3; col++ {
+		s.SetCell(x+col, y, bg, ' ')
+	}
+	for n, c := range entry.Swatches {
+		st := styles.Default.Background(c)
+		s.SetCell(x+3+n*2, y, st, ' ')
+		s.SetCell(x+4+n*2, y, st, ' ')
+	}
+}
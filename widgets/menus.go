@@ -7,28 +7,37 @@ import (
 
 type MenuFn func(tcell.Screen) MenuFn
 
-func HelpMenu(s tcell.Screen) MenuFn {
-	drawHelpMenu(s)
-	for {
-		ev := s.PollEvent()
-		switch ev.(type) {
-		case *tcell.EventKey:
-			return nil
-		case *tcell.EventResize:
-			return HelpMenu
+type HelpMenuItem struct {
+	Key  string
+	Desc string
+}
+
+// NewHelpMenu returns a MenuFn displaying the given items until a key is
+// pressed
+func NewHelpMenu(items []HelpMenuItem) MenuFn {
+	var menu MenuFn
+	menu = func(s tcell.Screen) MenuFn {
+		drawHelpMenu(s, items)
+		for {
+			switch s.PollEvent().(type) {
+			case *tcell.EventKey:
+				return nil
+			case *tcell.EventResize:
+				return menu
+			}
 		}
 	}
-	return nil
+	return menu
 }
 
-func drawHelpMenu(s tcell.Screen) {
+func drawHelpMenu(s tcell.Screen, items []HelpMenuItem) {
 	var maxL, maxR, menuW int
-	for _, item := range helpMenuItems {
-		if len(item.key) > maxL {
-			maxL = len(item.key)
+	for _, item := range items {
+		if len([]rune(item.Key)) > maxL {
+			maxL = len([]rune(item.Key))
 		}
-		if len(item.desc) > maxR {
-			maxR = len(item.desc)
+		if len(item.Desc) > maxR {
+			maxR = len(item.Desc)
 		}
 	}
 	menuW = maxL + maxR + 4
@@ -38,28 +47,15 @@ func drawHelpMenu(s tcell.Screen) {
 	x := (w - menuW) / 2
 	y := 2
 
-	for n, item := range helpMenuItems {
-		s.SetCell(x+1, y+n, styles.Default, []rune(item.key)...)
+	for n, item := range items {
+		for i, ch := range []rune(item.Key) {
+			s.SetCell(x+1+i, y+n, styles.Default, ch)
+		}
 		s.SetCell(x+maxL+2, y+n, styles.Default, '|')
-		s.SetCell(x+maxL+4, y+n, styles.Default, []rune(item.desc)...)
+		for i, ch := range []rune(item.Desc) {
+			s.SetCell(x+maxL+4+i, y+n, styles.Default, ch)
+		}
 	}
 
 	s.Show()
 }
-
-type helpMenuItem struct {
-	key  string
-	desc string
-}
-
-var helpMenuItems = []helpMenuItem{
-	{"↑, k", "navigate up"},
-	{"↓, j", "navigate down"},
-	{"←, h", "decrease selected value"},
-	{"→, l", "increase selected value"},
-	{"<shift> + ←/→/h/l", "more quickly increase/decrease selected value"},
-	{"a, <ins>", "add a new palette color"},
-	{"x, <del>", "remove the selected palette color"},
-	{"q, <esc>", "exit tcolors"},
-	{"?", "show this help menu"},
-}
@@ -15,6 +15,7 @@ type NavBar struct {
 	label  string
 	pos    int
 	offset int
+	x, y   int // screen position, as of last draw
 	width  int
 	height int
 	pst    tcell.Style // pointer style
@@ -32,6 +33,7 @@ func NewNavBar(s *state.State, length int) *NavBar {
 // of rows occupied
 func (bar *NavBar) Draw(x, y int, s tcell.Screen) int {
 	var st tcell.Style
+	bar.x, bar.y = x, y
 
 	n := bar.offset
 	col := 0
@@ -82,10 +84,21 @@ func (bar *NavBar) Resize(w, h int) {
 	bar.down(0)
 }
 
+// HitTest reports whether the given screen coordinates fall within bar
+func (bar *NavBar) HitTest(x, y int) bool {
+	return x >= bar.x && x < bar.x+bar.width && y >= bar.y && y <= bar.y+bar.height+1
+}
+
+// itemAt returns the index of the item drawn at screen column x
+func (bar *NavBar) itemAt(x int) int {
+	return clamp(bar.offset+x-bar.x, 0, len(bar.items)-1)
+}
+
 // NavBar implements Section
 func (bar *NavBar) Up(int)                         {}
 func (bar *NavBar) Down(int)                       {}
 func (bar *NavBar) Handle(state.Change)            {}
+func (bar *NavBar) Click(int, int)                 {}
 func (bar *NavBar) SetPointerStyle(st tcell.Style) { bar.pst = st }
 
 func (bar *NavBar) up(step int) {
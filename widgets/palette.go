@@ -18,6 +18,9 @@ type PaletteBox struct {
 	boxWidth  int
 	boxHeight int
 	xStretch  int
+	x, y      int         // screen position, as of last draw
+	height    int         // rows occupied, as of last draw
+	boxWidths []int       // width of each palette color box, as of last draw
 	pst       tcell.Style // pointer style
 	state     *state.State
 }
@@ -33,8 +36,8 @@ func (pb *PaletteBox) Draw(x, y int, s tcell.Screen) int {
 	activePaletteHeight := int(float64(pb.boxHeight)*2.5) - 1
 
 	pos := pb.state.Pos()
-	items := pb.state.SubColors()
-	selected := items[pos] // selected termbox color
+	items := pb.state.Swatches()
+	selected := items[pos] // selected termbox color, as displayed
 
 	// distribute stretch evenly across boxes
 	// where appropriate to facilitate centering
@@ -63,6 +66,7 @@ func (pb *PaletteBox) Draw(x, y int, s tcell.Screen) int {
 	for n := range boxWidths {
 		boxWidths[n] += pb.boxWidth
 	}
+	pb.x, pb.y, pb.boxWidths = x, y, boxWidths
 
 	// text box header
 	textBox := []rune(pb.text())
@@ -169,21 +173,18 @@ func (pb *PaletteBox) Draw(x, y int, s tcell.Screen) int {
 		lx += bw
 	}
 
-	return activePaletteHeight + pb.boxHeight + 4
+	pb.height = activePaletteHeight + pb.boxHeight + 4
+	return pb.height
 }
 
 func (pb *PaletteBox) text() string {
 	const spacer = "  ▎ "
 
-	txt := "▎"
-	selected := pb.state.SubColors()[pb.state.Pos()]
+	selected := pb.state.Selected()
+	txt := selected.RGBString()
+	txt += spacer + "#" + selected.HexString()
 
-	r, g, b := selected.RGB()
-	txt = fmt.Sprintf("%03d %03d %03d", r, g, b)
-
-	txt += spacer + "#" + pb.state.Selected().Hex()
-
-	h, s, l := pb.state.Selected().HSL()
+	h, s, l := selected.HSL()
 	txt += spacer + fmt.Sprintf("%03.0f %03.0f %03.0f", h, s, l)
 
 	return txt
@@ -195,6 +196,24 @@ func (pb *PaletteBox) Resize(w, h int) {
 	pb.width = w
 }
 
+// HitTest reports whether the given screen coordinates fall within pb
+func (pb *PaletteBox) HitTest(x, y int) bool {
+	return x >= pb.x && x < pb.x+pb.width && y >= pb.y && y < pb.y+pb.height
+}
+
+// Click selects the palette color whose box is drawn at the given screen
+// column
+func (pb *PaletteBox) Click(x, y int) {
+	lx := pb.x
+	for n, bw := range pb.boxWidths {
+		if x >= lx && x < lx+bw {
+			pb.state.SetPos(n)
+			return
+		}
+		lx += bw
+	}
+}
+
 func (pb *PaletteBox) Handle(state.Change)            {}
 func (pb *PaletteBox) Up(step int)                    { pb.state.Next() }
 func (pb *PaletteBox) Down(step int)                  { pb.state.Prev() }
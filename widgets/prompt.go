@@ -0,0 +1,87 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/bcicen/tcolors/styles"
+	"github.com/gdamore/tcell"
+)
+
+// PromptChoice is a single answer to a Prompt, selected by its key
+type PromptChoice struct {
+	Key   rune
+	Label string
+}
+
+// Prompt asks a question on the last screen row, waiting for one of a set
+// of single key answers
+type Prompt struct {
+	active   bool
+	question string
+	choices  []PromptChoice
+	onChoice func(rune)
+	width    int
+}
+
+func NewPrompt() *Prompt {
+	return &Prompt{}
+}
+
+// Ask activates the prompt with the given question and choices, calling
+// onChoice with the key of the chosen answer. The prompt is cancelled
+// without calling onChoice on escape.
+func (p *Prompt) Ask(question string, choices []PromptChoice, onChoice func(rune)) {
+	p.active = true
+	p.question = question
+	p.choices = choices
+	p.onChoice = onChoice
+}
+
+func (p *Prompt) Active() bool { return p.active }
+
+// HandleKey processes a key event while the prompt is active, returning
+// whether the prompt was answered or cancelled
+func (p *Prompt) HandleKey(ev *tcell.EventKey) (done bool) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		p.active = false
+		return true
+	case tcell.KeyRune:
+		r := unicode.ToLower(ev.Rune())
+		for _, c := range p.choices {
+			if c.Key == r {
+				p.active = false
+				p.onChoice(c.Key)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Draw redraws the prompt on the last screen row, if active
+func (p *Prompt) Draw(x int, s tcell.Screen) {
+	if !p.active {
+		return
+	}
+	_, h := s.Size()
+	y := h - 1
+
+	labels := make([]string, len(p.choices))
+	for n, c := range p.choices {
+		labels[n] = fmt.Sprintf("[%c]%s", c.Key, strings.TrimPrefix(c.Label, string(c.Key)))
+	}
+	text := []rune(p.question + " " + strings.Join(labels, " "))
+
+	for i := 0; i <= p.width; i++ {
+		ch := ' '
+		if i < len(text) {
+			ch = text[i]
+		}
+		s.SetCell(x+i, y, styles.TextBox, ch)
+	}
+}
+
+func (p *Prompt) Resize(w int) { p.width = w }
@@ -68,6 +68,12 @@ func (bar *SaturationBar) Down(step int) {
 	bar.setState()
 }
 
+// Click sets the value at the given screen column
+func (bar *SaturationBar) Click(x, y int) {
+	bar.SetPos(bar.itemAt(x))
+	bar.setState()
+}
+
 func (bar *SaturationBar) setState() {
 	bar.state.SetSaturation(bar.scale[bar.pos])
 }
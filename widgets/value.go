@@ -59,6 +59,12 @@ func (bar *ValueBar) Down(step int) {
 	bar.setState()
 }
 
+// Click sets the value at the given screen column
+func (bar *ValueBar) Click(x, y int) {
+	bar.SetPos(bar.itemAt(x))
+	bar.setState()
+}
+
 func (bar *ValueBar) setState() {
 	bar.state.SetValue(bar.scale[bar.pos])
 }